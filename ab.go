@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// StoreAB snapshots the current effect settings as the reference side of the
+// A/B comparison, replacing any earlier one.
+func (i *Instrument) StoreAB() {
+	i.mu.Lock()
+	speaker.Lock()
+	stored := i.effectSettings()
+	speaker.Unlock()
+	i.abOther = &stored
+	i.abReference = false
+	i.mu.Unlock()
+	log.Printf("🅰️  Efeitos de '%s' guardados para comparação A/B.", i.name)
+}
+
+// ToggleAB swaps the effect settings being heard with the other side of the
+// A/B comparison: the stored reference or the edits made since. Every
+// parameter changes in the same audio callback. It reports whether the
+// reference is now the one playing.
+func (i *Instrument) ToggleAB() (reference bool, err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.abOther == nil {
+		return false, errorf(ErrInvalidState, "nenhuma referência A/B guardada para '%s' (use 'ab store')", i.name)
+	}
+	speaker.Lock()
+	current := i.effectSettings()
+	i.setEffects(*i.abOther)
+	speaker.Unlock()
+	i.abOther = &current
+	i.abReference = !i.abReference
+	return i.abReference, nil
+}
+
+func handleABCommand(dj *DJMixer, args []string) error {
+	if len(args) < 2 || (args[0] != "store" && args[0] != "toggle") {
+		return fmt.Errorf("uso: ab store|toggle <instrumento>")
+	}
+	inst, ok := dj.GetInstrument(args[1])
+	if !ok {
+		return instrumentNotFound(args[1])
+	}
+	if args[0] == "store" {
+		inst.StoreAB()
+		return nil
+	}
+	reference, err := inst.ToggleAB()
+	if err != nil {
+		return err
+	}
+	if reference {
+		log.Printf("🅰️  '%s': ouvindo a referência (A).", inst.name)
+	} else {
+		log.Printf("🅱️  '%s': ouvindo as edições (B).", inst.name)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// Align seeks a playing instrument by the smallest amount that puts its beats,
+// and so its loop start, on the master beat grid. Its tempo is left alone;
+// this is a one-off correction, not a sync.
+func (dj *DJMixer) Align(name string) error {
+	inst, ok := dj.GetInstrument(name)
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	return inst.alignTo(dj.clock)
+}
+
+func (i *Instrument) alignTo(clock *beatClock) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.state != StatePlaying {
+		return errorf(ErrInvalidState, "'%s' precisa estar tocando para ser alinhado", i.name)
+	}
+	speaker.Lock()
+	// File samples per beat at the file's own tempo, whatever it plays at.
+	perBeat := float64(i.format.SampleRate) * 60 / BaseBPM
+	start, end := i.loop.region()
+	length := end - start
+	heard, wrapped := i.heardSource()
+	_, gridPhase := math.Modf(clock.Beat())
+	_, phase := math.Modf(heard / perBeat)
+	shift := gridPhase - phase
+	shift -= math.Round(shift) // the nearest grid beat, at most half a beat away
+	// The resampler plays out what it has read ahead before the new position,
+	// so seek the source that far past the target.
+	ahead := float64(i.streamer.Position()) - heard
+	if wrapped {
+		ahead += float64(length)
+	}
+	target := (int(math.Round(heard+shift*perBeat+ahead)) - start) % length
+	if target < 0 {
+		target += length
+	}
+	target += start
+	err := i.seek(target)
+	speaker.Unlock()
+	if err != nil {
+		return err
+	}
+	moved := time.Duration(shift * 60 / (BaseBPM * i.speedRatio) * float64(time.Second))
+	log.Printf("📐 '%s' alinhado à grade: %+.2f batida (%+dms).", i.name, shift, moved.Milliseconds())
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// parseFloatArg parses a numeric command argument; what names it in the error,
+// e.g. "profundidade do chorus". NaN and infinities are refused: they would
+// slip past every range check, since any comparison with NaN is false, and a
+// single one in a filter's state silences it for good.
+func parseFloatArg(what, s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("valor inválido para %s: %s", what, s)
+	}
+	return v, nil
+}
+
+// parseRange parses a numeric argument that must lie in [lo, hi].
+func parseRange(what, s string, lo, hi float64) (float64, error) {
+	v, err := parseFloatArg(what, s)
+	if err != nil {
+		return 0, err
+	}
+	if v < lo || v > hi {
+		return 0, errorf(ErrOutOfRange, "%s %g está fora do intervalo [%g, %g]", what, v, lo, hi)
+	}
+	return v, nil
+}
+
+// parseIntArg parses an integer command argument, such as a count of bars or
+// voices; what names it in the error.
+func parseIntArg(what, s string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("valor inválido para %s: %s", what, s)
+	}
+	return v, nil
+}
+
+// parseIntRange parses an integer argument that must lie in [lo, hi].
+func parseIntRange(what, s string, lo, hi int) (int, error) {
+	v, err := parseIntArg(what, s)
+	if err != nil {
+		return 0, err
+	}
+	if v < lo || v > hi {
+		return 0, errorf(ErrOutOfRange, "%s %d está fora do intervalo [%d, %d]", what, v, lo, hi)
+	}
+	return v, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// Arm marks instruments to be started together by the next Go.
+func (dj *DJMixer) Arm(names []string) error {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	for _, name := range names {
+		if _, ok := dj.instruments[name]; !ok {
+			return instrumentNotFound(name)
+		}
+	}
+	if dj.armed == nil {
+		dj.armed = make(map[string]bool)
+	}
+	for _, name := range names {
+		dj.armed[name] = true
+	}
+	log.Printf("🎯 Armado(s): %s.", strings.Join(names, ", "))
+	return nil
+}
+
+// Disarm clears the armed set without starting anything.
+func (dj *DJMixer) Disarm() {
+	dj.mu.Lock()
+	dj.armed = nil
+	dj.mu.Unlock()
+	log.Println("🎯 Nenhum instrumento armado.")
+}
+
+// ArmedNames returns the armed instruments, sorted.
+func (dj *DJMixer) ArmedNames() []string {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	names := make([]string, 0, len(dj.armed))
+	for name := range dj.armed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Go starts every armed instrument within a single speaker lock, so they all
+// begin on the same sample boundary, then clears the armed set.
+func (dj *DJMixer) Go() error {
+	dj.mu.Lock()
+	armed := make([]*Instrument, 0, len(dj.armed))
+	for name := range dj.armed {
+		if inst, ok := dj.instruments[name]; ok {
+			armed = append(armed, inst)
+		}
+	}
+	dj.armed = nil
+	dj.mu.Unlock()
+	if len(armed) == 0 {
+		return fmt.Errorf("nenhum instrumento armado; use 'arm <nomes...>' primeiro")
+	}
+	// Always lock instruments in name order so two concurrent Go calls cannot
+	// deadlock each other.
+	sort.Slice(armed, func(a, b int) bool { return armed[a].name < armed[b].name })
+	for _, inst := range armed {
+		inst.mu.Lock()
+	}
+	speaker.Lock()
+	for _, inst := range armed {
+		if inst.eos.ended {
+			if err := inst.rewind(); err != nil {
+				log.Printf("⚠️  Falha ao reiniciar '%s': %v", inst.name, err)
+				continue
+			}
+			inst.resetEffectState()
+		}
+		inst.volume.Silent = false
+		inst.ctrl.Paused = false
+		inst.state = StatePlaying
+	}
+	speaker.Unlock()
+	names := make([]string, len(armed))
+	for k, inst := range armed {
+		names[k] = inst.name
+		inst.mu.Unlock()
+	}
+	log.Printf("🚀 Disparados juntos: %s.", strings.Join(names, ", "))
+	return nil
+}
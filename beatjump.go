@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// BeatJump moves the playhead by beats of a grid at bpm, forwards or, when
+// negative, backwards, wrapping around the loop. A beat is as much of the file
+// as the instrument plays in one beat at its current speed, so on a synced
+// instrument a jump of 4 lands exactly one bar away.
+func (i *Instrument) BeatJump(beats, bpm float64) error {
+	if bpm <= 0 || math.IsNaN(beats) || math.IsInf(beats, 0) {
+		return fmt.Errorf("salto inválido: %g batidas a %g BPM", beats, bpm)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	outputRate := float64(i.format.SampleRate) / i.rateRatio
+	perBeat := outputRate * 60 / bpm * i.resampleRatio()
+	delta := int(math.Round(beats * perBeat))
+	speaker.Lock()
+	start, end := i.loop.region()
+	length := end - start
+	pos := (i.streamer.Position() - start + delta) % length
+	if pos < 0 {
+		pos += length
+	}
+	err := i.seek(start + pos)
+	speaker.Unlock()
+	if err != nil {
+		return err
+	}
+	log.Printf("⏩ '%s' saltou %+g batida(s).", i.name, beats)
+	return nil
+}
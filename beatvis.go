@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"time"
+)
+
+const (
+	// beatFlash is how long the indicator stays lit on each beat.
+	beatFlash = 100 * time.Millisecond
+	// beatsPerBar sets where the bar count in the indicator wraps.
+	beatsPerBar = 4
+	// windowTitle is put back when the indicator is turned off.
+	windowTitle = "Go DJ"
+)
+
+// setWindowTitle sets the terminal window title with an OSC escape. The title
+// bar is outside the text area, so updating it never disturbs the prompt or
+// the command being typed.
+func setWindowTitle(w io.Writer, title string) {
+	fmt.Fprintf(w, "\x1b]2;%s\x07", title)
+}
+
+// SetBeatVisual starts or stops flashing the beat of the master clock in the
+// terminal window title, as a visual metronome while typing.
+func (dj *DJMixer) SetBeatVisual(on bool) error {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	if on == (dj.cancelBeatVis != nil) {
+		if on {
+			return errorf(ErrInvalidState, "indicador de batida já está ligado")
+		}
+		return errorf(ErrInvalidState, "indicador de batida não está ligado")
+	}
+	if !on {
+		dj.cancelBeatVis()
+		dj.cancelBeatVis = nil
+		log.Println("🥁 Indicador de batida desligado.")
+		return nil
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return errorf(ErrInvalidState, "indicador de batida precisa de um terminal")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	dj.cancelBeatVis = cancel
+	go dj.runBeatVisual(ctx, os.Stdout)
+	log.Println("🥁 Indicador de batida ligado na barra de título do terminal.")
+	return nil
+}
+
+// runBeatVisual lights the indicator on every beat of the master clock until
+// ctx is canceled. Each wait is worked out from the clock afresh, so tempo
+// changes and ramps are followed without drift.
+func (dj *DJMixer) runBeatVisual(ctx context.Context, w io.Writer) {
+	defer setWindowTitle(w, windowTitle)
+	for {
+		beat := dj.clock.Beat()
+		next := math.Floor(beat) + 1
+		wait := time.Duration((next - beat) / dj.clock.BPM() * float64(time.Minute))
+		if !sleepCtx(ctx, wait) {
+			return
+		}
+		count := int(next)%beatsPerBar + 1
+		bpm := dj.clock.BPM()
+		setWindowTitle(w, fmt.Sprintf("● %d/%d — %.1f BPM — %s", count, beatsPerBar, bpm, windowTitle))
+		if !sleepCtx(ctx, beatFlash) {
+			return
+		}
+		setWindowTitle(w, fmt.Sprintf("○ %d/%d — %.1f BPM — %s", count, beatsPerBar, bpm, windowTitle))
+	}
+}
+
+// sleepCtx waits for d, returning false if ctx is canceled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// MaxBinding is the highest number slot an instrument can be bound to.
+const MaxBinding = 9
+
+// Bind puts an instrument on a number slot, so "1 play" or "1 vol 0.5" control
+// it without typing its name.
+func (dj *DJMixer) Bind(slot int, name string) error {
+	if slot < 1 || slot > MaxBinding {
+		return errorf(ErrOutOfRange, "slot %d está fora do intervalo [1, %d]", slot, MaxBinding)
+	}
+	inst, ok := dj.GetInstrument(name)
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	dj.mu.Lock()
+	if dj.bindings == nil {
+		dj.bindings = make(map[int]string)
+	}
+	dj.bindings[slot] = inst.name
+	dj.mu.Unlock()
+	log.Printf("🔢 %d → '%s'.", slot, inst.name)
+	return nil
+}
+
+// Unbind empties a number slot.
+func (dj *DJMixer) Unbind(slot int) error {
+	dj.mu.Lock()
+	name, ok := dj.bindings[slot]
+	delete(dj.bindings, slot)
+	dj.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("slot %d não está associado", slot)
+	}
+	log.Printf("🔢 %d não aponta mais para '%s'.", slot, name)
+	return nil
+}
+
+// expandBinding rewrites "<slot> <command> [args...]" as "<command> <name>
+// [args...]" when the slot is bound.
+func (dj *DJMixer) expandBinding(input string) (string, error) {
+	fields := strings.Fields(input)
+	if len(fields[0]) != 1 || fields[0][0] < '1' || fields[0][0] > '0'+MaxBinding {
+		return input, nil
+	}
+	slot := int(fields[0][0] - '0')
+	dj.mu.RLock()
+	name, ok := dj.bindings[slot]
+	dj.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("slot %d não está associado (use 'bind %d <instrumento>')", slot, slot)
+	}
+	if len(fields) < 2 {
+		return "", fmt.Errorf("uso: %d <comando> [argumentos...]", slot)
+	}
+	return strings.Join(append([]string{fields[1], name}, fields[2:]...), " "), nil
+}
+
+// printBindings lists the bound number slots.
+func (dj *DJMixer) printBindings(out io.Writer) {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	if len(dj.bindings) == 0 {
+		fmt.Fprintln(out, "Nenhum slot associado.")
+		return
+	}
+	for slot := 1; slot <= MaxBinding; slot++ {
+		if name, ok := dj.bindings[slot]; ok {
+			fmt.Fprintf(out, "  %d → %s\n", slot, name)
+		}
+	}
+}
+
+// handleBindCommand runs "bind", "bind <n> <name>" and "bind <n> off".
+func handleBindCommand(dj *DJMixer, out io.Writer, args []string) error {
+	if len(args) == 0 {
+		dj.printBindings(out)
+		return nil
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("uso: bind <1-%d> <instrumento>|off", MaxBinding)
+	}
+	slot, err := parseIntRange("slot", args[0], 1, MaxBinding)
+	if err != nil {
+		return err
+	}
+	if args[1] == "off" {
+		return dj.Unbind(slot)
+	}
+	return dj.Bind(slot, args[1])
+}
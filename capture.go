@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// MaxCaptureBars bounds captureloop, which holds the whole recording in
+// memory: 16 bars at 60 BPM and 48 kHz is about 50 MB.
+const MaxCaptureBars = 16
+
+// captureNamePrefix names captured loops that weren't given a name: capture1,
+// capture2 and so on.
+const captureNamePrefix = "capture"
+
+// masterTap copies the master output into buf while a capture is armed. The
+// first skip frames go by unrecorded so the take starts on a bar line; done is
+// closed once buf is full. It runs on the audio thread, so buf is allocated
+// beforehand.
+type masterTap struct {
+	streamer beep.Streamer
+	skip     int
+	buf      [][2]float64 // nil when no capture is armed
+	filled   int
+	done     chan struct{}
+}
+
+func (t *masterTap) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = t.streamer.Stream(samples)
+	if t.buf == nil {
+		return n, ok
+	}
+	s := samples[:n]
+	skipped := min(t.skip, len(s))
+	t.skip -= skipped
+	t.filled += copy(t.buf[t.filled:], s[skipped:])
+	if t.filled == len(t.buf) {
+		t.buf = nil
+		close(t.done)
+	}
+	return n, ok
+}
+
+func (t *masterTap) Err() error {
+	return t.streamer.Err()
+}
+
+// memorySource plays a recording held in memory as an instrument source.
+type memorySource struct {
+	samples [][2]float64
+	pos     int
+}
+
+func (m *memorySource) Stream(samples [][2]float64) (n int, ok bool) {
+	if m.pos >= len(m.samples) {
+		return 0, false
+	}
+	n = copy(samples, m.samples[m.pos:])
+	m.pos += n
+	return n, true
+}
+
+func (m *memorySource) Err() error    { return nil }
+func (m *memorySource) Len() int      { return len(m.samples) }
+func (m *memorySource) Position() int { return m.pos }
+func (m *memorySource) Close() error  { return nil }
+
+func (m *memorySource) Seek(p int) error {
+	if p < 0 || p > len(m.samples) {
+		return fmt.Errorf("posição %d fora da gravação (0 a %d)", p, len(m.samples))
+	}
+	m.pos = p
+	return nil
+}
+
+// CaptureLoop records bars of the master output, starting at the next bar line
+// of the master clock, and adds the take as a new stopped instrument called
+// name, or captureN when name is empty. The recording runs in the
+// background; the instrument appears when it ends. It is tagged with the
+// master tempo it was played at, so it follows later tempo changes like any
+// other loop.
+func (dj *DJMixer) CaptureLoop(bars int, name string) error {
+	if bars < 1 || bars > MaxCaptureBars {
+		return errorf(ErrOutOfRange, "duração da captura %d está fora do intervalo [1, %d] compassos", bars, MaxCaptureBars)
+	}
+	if name == "" {
+		name = dj.nextCaptureName()
+	} else if _, exists := dj.GetInstrument(name); exists {
+		return errorf(ErrInstrumentExists, "instrumento '%s' já existe", name)
+	}
+	bpm := dj.MasterBPM()
+	barLen := float64(dj.sampleRate) * 60 / bpm * beatsPerBar
+	buf := make([][2]float64, int(math.Round(barLen*float64(bars))))
+	beat := dj.clock.Beat()
+	untilBar := (math.Floor(beat/beatsPerBar)+1)*beatsPerBar - beat
+	wait := time.Duration(untilBar / bpm * float64(time.Minute))
+	done := make(chan struct{})
+
+	speaker.Lock()
+	tap := dj.master.tap
+	if tap.buf != nil {
+		speaker.Unlock()
+		return errorf(ErrInvalidState, "já há uma captura em andamento")
+	}
+	tap.skip, tap.buf, tap.filled, tap.done = dj.sampleRate.N(wait), buf, 0, done
+	speaker.Unlock()
+	log.Printf("⏺️  Capturando %d compasso(s) da saída mestre a partir do próximo compasso...", bars)
+
+	go func() {
+		// If the audio device stalls the take never fills; give up rather than
+		// leave the tap armed forever.
+		timeout := wait + dj.sampleRate.D(len(buf)) + 5*time.Second
+		select {
+		case <-done:
+		case <-time.After(timeout):
+			speaker.Lock()
+			if tap.done == done {
+				tap.buf = nil
+			}
+			speaker.Unlock()
+			log.Printf("❌ Captura de '%s' abandonada: a saída mestre parou de tocar.", name)
+			return
+		}
+		if err := dj.addCapture(name, buf, bpm); err != nil {
+			log.Printf("❌ Captura de '%s' não foi adicionada: %v", name, err)
+			return
+		}
+		log.Printf("✅ Loop capturado como '%s' (%d compasso(s) a %.1f BPM).", name, bars, bpm)
+	}()
+	return nil
+}
+
+// addCapture turns a recording made at bpm into an instrument. Files are taken
+// to be at BaseBPM, so the take is given the sample rate at which it would be
+// BaseBPM; the resampler converts it like any file's, and the grid features
+// (align, offset) count its beats right.
+func (dj *DJMixer) addCapture(name string, samples [][2]float64, bpm float64) error {
+	rate := beep.SampleRate(math.Round(float64(dj.sampleRate) * BaseBPM / bpm))
+	format := beep.Format{SampleRate: rate, NumChannels: 2, Precision: 2}
+	inst := newInstrument(name, &memorySource{samples: samples}, format)
+	inst.matchSampleRate(dj.sampleRate)
+	if err := dj.addInstrument(inst); err != nil {
+		return err
+	}
+	inst.applySpeed(inst.clampSpeed(dj.MasterBPM() / BaseBPM))
+	return nil
+}
+
+// nextCaptureName returns the first free captureN name.
+func (dj *DJMixer) nextCaptureName() string {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("%s%d", captureNamePrefix, n)
+		if _, exists := dj.GetInstrument(name); !exists {
+			return name
+		}
+	}
+}
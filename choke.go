@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// SetChokeGroup makes the named instruments an exclusive group: playing one of
+// them stops the others, like open and closed hi-hats. An instrument belongs to
+// at most one group, so assigning it here takes it out of any other.
+func (dj *DJMixer) SetChokeGroup(group string, names []string) error {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	for k, name := range names {
+		name = dj.resolveName(name)
+		if _, ok := dj.instruments[name]; !ok {
+			return instrumentNotFound(name)
+		}
+		names[k] = name
+	}
+	if dj.chokeGroups == nil {
+		dj.chokeGroups = make(map[string]string)
+	}
+	for _, name := range names {
+		dj.chokeGroups[name] = group
+	}
+	log.Printf("🥢 Grupo exclusivo '%s': %s.", group, strings.Join(dj.chokeMembers(group), ", "))
+	return nil
+}
+
+// ClearChokeGroup dissolves a group.
+func (dj *DJMixer) ClearChokeGroup(group string) error {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	members := dj.chokeMembers(group)
+	if len(members) == 0 {
+		return fmt.Errorf("grupo exclusivo '%s' não encontrado", group)
+	}
+	for _, name := range members {
+		delete(dj.chokeGroups, name)
+	}
+	log.Printf("🥢 Grupo exclusivo '%s' desfeito.", group)
+	return nil
+}
+
+// chokeMembers returns the sorted members of group; the caller must hold dj.mu.
+func (dj *DJMixer) chokeMembers(group string) []string {
+	var members []string
+	for name, g := range dj.chokeGroups {
+		if g == group {
+			members = append(members, name)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// choke stops the other members of inst's group, if it has one. It is called
+// after inst starts playing.
+func (dj *DJMixer) choke(inst *Instrument) {
+	dj.mu.RLock()
+	var others []*Instrument
+	if group, ok := dj.chokeGroups[inst.name]; ok {
+		for _, name := range dj.chokeMembers(group) {
+			if other := dj.instruments[name]; other != nil && other != inst {
+				others = append(others, other)
+			}
+		}
+	}
+	dj.mu.RUnlock()
+	for _, other := range others {
+		if other.GetState() == StatePlaying {
+			_ = other.Stop()
+		}
+	}
+}
+
+func printChokeGroups(dj *DJMixer, out io.Writer) {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	groups := make(map[string]bool)
+	for _, g := range dj.chokeGroups {
+		groups[g] = true
+	}
+	names := make([]string, 0, len(groups))
+	for g := range groups {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(out, "--- Grupos exclusivos ---")
+	for _, g := range names {
+		fmt.Fprintf(out, " 🥢 %-10s %s\n", g, strings.Join(dj.chokeMembers(g), ", "))
+	}
+	fmt.Fprintln(out, "-------------------------")
+}
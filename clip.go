@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// DefaultClipDB is the peak level, in dBFS, from which list lights the clip
+// LED when the config doesn't say.
+const (
+	DefaultClipDB = -1.0
+	MinClipDB     = -24.0
+	MaxClipDB     = 0.0
+)
+
+// clipHold is how long a peak keeps the LED lit, so short overs aren't missed
+// between two list commands.
+const clipHold = time.Second
+
+// SetClipThreshold sets the peak level, in dBFS, at which list flags an
+// instrument or the master as clipping.
+func (dj *DJMixer) SetClipThreshold(db float64) error {
+	if !within(db, MinClipDB, MaxClipDB) {
+		return errorf(ErrOutOfRange, "limiar de clip %.1f dB está fora do intervalo [%.0f, %.0f]", db, MinClipDB, MaxClipDB)
+	}
+	speaker.Lock()
+	dj.master.clipDB = db
+	speaker.Unlock()
+	log.Printf("🔴 Indicador de clip acende a partir de %.1f dBFS.", db)
+	return nil
+}
+
+// ClipThreshold returns the clip LED threshold in dBFS.
+func (dj *DJMixer) ClipThreshold() float64 {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return dj.master.clipDB
+}
+
+// clipping reports whether the meter peaked at or above the threshold
+// recently.
+func (m *levelMeter) clipping(thresholdDB float64) bool {
+	return m.recentPeak(clipHold) >= math.Pow(10, thresholdDB/20)
+}
+
+// clipLED is the suffix list shows for a meter that is clipping.
+func clipLED(m *levelMeter, thresholdDB float64) string {
+	if m.clipping(thresholdDB) {
+		return " 🔴 CLIP"
+	}
+	return ""
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// DefaultConfigFile is read at startup when --config is not given. A missing
+// file simply means the defaults are used.
+const DefaultConfigFile = "go-dj.json"
+
+// Config holds the user settings read from the JSON config file.
+type Config struct {
+	// Aliases maps a custom command name to the command it expands to, e.g.
+	// {"p": "play", "v": "volume"}.
+	Aliases map[string]string `json:"aliases"`
+	// AllowAliasOverride lets aliases shadow built-in command names.
+	AllowAliasOverride bool `json:"allowAliasOverride"`
+	// DimDB is how much "dim" drops the master, in dB (e.g. -20).
+	DimDB *float64 `json:"dimDb"`
+	// ClipDB is the peak level, in dBFS, that lights the clip LED in list.
+	ClipDB *float64 `json:"clipDb"`
+	// Icons picks the icon theme, "emoji" or "plain"; --icons overrides it.
+	Icons string `json:"icons"`
+	// Prompt is the command prompt, with {bpm}, {playing}, {total} and {vol}
+	// filled in each time it is shown, e.g. "[{bpm}bpm {playing}▶] > ".
+	Prompt string `json:"prompt"`
+}
+
+// builtinCommands lists every command name handleCommand understands, so
+// aliases can be kept from shadowing them.
+var builtinCommands = map[string]bool{
+	"play": true, "start": true, "pause": true, "stop": true, "replay": true,
+	"playall": true, "pauseall": true, "stopall": true, "replayall": true,
+	"playfrom": true, "volume": true, "vol": true, "bpm": true, "masterbpm": true,
+	"ramp": true, "trim": true, "flanger": true, "chorus": true, "freeze": true,
+	"drive": true, "width": true, "chanvol": true, "chanmute": true, "duck": true,
+	"repeat": true, "loop": true, "reset": true, "testtone": true, "noise": true,
+	"undo": true, "u": true, "scene": true, "status": true, "history": true,
+	"files": true, "mixdown": true, "mono": true, "mastervol": true, "dim": true,
+	"swaplr": true, "preview": true, "limits": true, "arm": true, "go": true,
+	"playlist": true, "pl": true, "chokegroup": true, "sleep": true,
+	"humanize": true, "load": true, "enable": true, "disable": true,
+	"waveform": true, "wave": true, "solo": true, "solosafe": true, "keys": true,
+	"fade": true, "fadecurve": true, "retrigger": true, "reload": true,
+	"swing": true, "metronome": true, "mastereq": true, "statusjson": true,
+	"list": true, "ls": true, "help": true, "h": true, "quit": true,
+	"exit": true, "q": true, "quality": true, "version": true, "clip": true,
+	"detune": true, "poly": true, "beatvis": true, "rate": true,
+	"xfader": true, "xfadercurve": true, "preset": true,
+	"link": true, "unlink": true, "wait": true,
+	"phaseinvert": true, "align": true, "roll": true, "offset": true, "np": true, "session": true,
+	"reinit-audio": true, "cue": true,
+	"lowpass": true, "autofilter": true, "trim-silence": true, "pattern": true, "stop-mode": true,
+	"masterreverb": true, "masterdelay": true, "mastercomp": true, "masterlimit": true, "rack": true, "bind": true, "tempo-follow": true,
+	"kill": true, "ab": true, "dump": true, "captureloop": true,
+	"exclude": true, "include": true, "jump": true,
+}
+
+// loadConfig reads the config file at path. A missing file yields an empty
+// config; a malformed one is an error.
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler configuração %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("configuração inválida em %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ApplyConfig installs the settings from cfg on the running mixer.
+func (dj *DJMixer) ApplyConfig(cfg *Config) {
+	aliases := make(map[string]string, len(cfg.Aliases))
+	for alias, target := range cfg.Aliases {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		target = strings.ToLower(strings.TrimSpace(target))
+		if alias == "" || target == "" || strings.ContainsAny(alias, " \t") {
+			log.Printf("⚠️  Alias inválido ignorado: '%s' → '%s'", alias, target)
+			continue
+		}
+		if builtinCommands[alias] && !cfg.AllowAliasOverride {
+			log.Printf("⚠️  Alias '%s' ignorado: sobrescreveria um comando existente (use allowAliasOverride).", alias)
+			continue
+		}
+		aliases[alias] = target
+	}
+	dj.mu.Lock()
+	dj.aliases = aliases
+	dj.prompt = cfg.Prompt
+	dj.config = cfg
+	dj.mu.Unlock()
+	if cfg.DimDB != nil {
+		if *cfg.DimDB > 0 {
+			log.Printf("⚠️  dimDb deve ser negativo; usando %.0f dB.", -*cfg.DimDB)
+		}
+		speaker.Lock()
+		dj.master.dimDB = -math.Abs(*cfg.DimDB)
+		speaker.Unlock()
+	}
+	if cfg.ClipDB != nil {
+		if db := *cfg.ClipDB; db < MinClipDB || db > MaxClipDB {
+			log.Printf("⚠️  clipDb %.1f ignorado: fora do intervalo [%.0f, %.0f].", db, MinClipDB, MaxClipDB)
+		} else {
+			speaker.Lock()
+			dj.master.clipDB = db
+			speaker.Unlock()
+		}
+	}
+	if cfg.Icons != "" && !flagSet("icons") {
+		if err := setIconTheme(cfg.Icons); err != nil {
+			log.Printf("⚠️  icons ignorado: %v", err)
+		}
+	}
+	if len(aliases) > 0 {
+		log.Printf("⚙️  %d alias(es) de comando carregado(s).", len(aliases))
+	}
+}
+
+// ReloadConfig re-reads the config file and applies it to the running mixer
+// without touching audio, logging every setting that changed. A file that
+// fails to load leaves the current settings in place. Every key in the config
+// file applies live, so nothing here needs a restart.
+func (dj *DJMixer) ReloadConfig(path string) {
+	cfg, err := loadConfig(path)
+	if err != nil {
+		log.Printf("⚠️  Configuração não recarregada: %v", err)
+		return
+	}
+	dj.mu.RLock()
+	prev := dj.config
+	dj.mu.RUnlock()
+	if prev == nil {
+		prev = &Config{}
+	}
+	changes := configChanges(prev, cfg)
+	if len(changes) == 0 {
+		log.Printf("⚙️  Configuração recarregada de %s: nada mudou.", path)
+		return
+	}
+	log.Printf("⚙️  Configuração recarregada de %s:", path)
+	for _, c := range changes {
+		log.Printf("   • %s", c)
+	}
+	dj.ApplyConfig(cfg)
+	// A key removed from the file goes back to its default.
+	speaker.Lock()
+	if prev.DimDB != nil && cfg.DimDB == nil {
+		dj.master.dimDB = DefaultDimDB
+	}
+	if prev.ClipDB != nil && cfg.ClipDB == nil {
+		dj.master.clipDB = DefaultClipDB
+	}
+	speaker.Unlock()
+	if prev.Icons != "" && cfg.Icons == "" && !flagSet("icons") {
+		_ = setIconTheme(*iconStyle)
+	}
+}
+
+// configChanges describes what differs between two configs.
+func configChanges(prev, next *Config) []string {
+	var changes []string
+	for alias, target := range next.Aliases {
+		if old, ok := prev.Aliases[alias]; !ok {
+			changes = append(changes, fmt.Sprintf("alias '%s' → '%s' adicionado", alias, target))
+		} else if old != target {
+			changes = append(changes, fmt.Sprintf("alias '%s' agora aponta para '%s' (era '%s')", alias, target, old))
+		}
+	}
+	for alias := range prev.Aliases {
+		if _, ok := next.Aliases[alias]; !ok {
+			changes = append(changes, fmt.Sprintf("alias '%s' removido", alias))
+		}
+	}
+	sort.Strings(changes)
+	if prev.AllowAliasOverride != next.AllowAliasOverride {
+		changes = append(changes, fmt.Sprintf("allowAliasOverride: %t → %t", prev.AllowAliasOverride, next.AllowAliasOverride))
+	}
+	if c, changed := dbSettingChange("dimDb", prev.DimDB, next.DimDB); changed {
+		changes = append(changes, c)
+	}
+	if c, changed := dbSettingChange("clipDb", prev.ClipDB, next.ClipDB); changed {
+		changes = append(changes, c)
+	}
+	if prev.Icons != next.Icons {
+		changes = append(changes, fmt.Sprintf("icons: '%s' → '%s'", prev.Icons, next.Icons))
+	}
+	if prev.Prompt != next.Prompt {
+		changes = append(changes, fmt.Sprintf("prompt: '%s' → '%s'", prev.Prompt, next.Prompt))
+	}
+	return changes
+}
+
+// dbSettingChange describes a change to an optional dB setting.
+func dbSettingChange(key string, prev, next *float64) (string, bool) {
+	format := func(v *float64) string {
+		if v == nil {
+			return "padrão"
+		}
+		return fmt.Sprintf("%.1f dB", *v)
+	}
+	if (prev == nil) == (next == nil) && (prev == nil || *prev == *next) {
+		return "", false
+	}
+	return fmt.Sprintf("%s: %s → %s", key, format(prev), format(next)), true
+}
+
+// expandAlias rewrites the first token of input if it is an alias.
+func (dj *DJMixer) expandAlias(input string) string {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return input
+	}
+	dj.mu.RLock()
+	target, ok := dj.aliases[strings.ToLower(fields[0])]
+	dj.mu.RUnlock()
+	if !ok {
+		return input
+	}
+	return strings.Join(append([]string{target}, fields[1:]...), " ")
+}
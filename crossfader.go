@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// XfaderCurve is how the crossfader position maps to the two deck levels.
+type XfaderCurve int
+
+const (
+	// XfaderSmooth blends with DefaultCrossfadeCurve, for long mixes.
+	XfaderSmooth XfaderCurve = iota
+	// XfaderCut brings a deck to full level within the first few percent of
+	// travel, like a scratch mixer's sharp curve, for fast cuts.
+	XfaderCut
+)
+
+// crossfadeCutLap is how much travel at each end the cut curve uses to bring
+// a deck in.
+const crossfadeCutLap = 0.05
+
+var xfaderCurveNames = []string{"smooth", "cut"}
+
+func (c XfaderCurve) String() string {
+	return xfaderCurveNames[c]
+}
+
+func parseXfaderCurve(s string) (XfaderCurve, error) {
+	for k, name := range xfaderCurveNames {
+		if s == name {
+			return XfaderCurve(k), nil
+		}
+	}
+	return 0, fmt.Errorf("curva do crossfader desconhecida: '%s' (use %s)", s, strings.Join(xfaderCurveNames, " ou "))
+}
+
+// crossfader blends two instruments, the decks. pos runs from 0 (only deck A)
+// to 1 (only deck B).
+type crossfader struct {
+	a, b  string
+	pos   float64
+	curve XfaderCurve
+}
+
+// gains returns the linear levels of decks A and B.
+func (x crossfader) gains() (a, b float64) {
+	if x.curve == XfaderCut {
+		return math.Min(1, (1-x.pos)/crossfadeCutLap), math.Min(1, x.pos/crossfadeCutLap)
+	}
+	return DefaultCrossfadeCurve.gain(1 - x.pos), DefaultCrossfadeCurve.gain(x.pos)
+}
+
+// AssignDecks puts instruments a and b on the two sides of the crossfader. The
+// decks it replaces go back to full level.
+func (dj *DJMixer) AssignDecks(a, b string) error {
+	instA, okA := dj.GetInstrument(a)
+	if !okA {
+		return instrumentNotFound(a)
+	}
+	instB, okB := dj.GetInstrument(b)
+	if !okB {
+		return instrumentNotFound(b)
+	}
+	if instA == instB {
+		return errorf(ErrInvalidState, "os dois lados do crossfader precisam de instrumentos diferentes")
+	}
+	dj.releaseDecks()
+	dj.mu.Lock()
+	dj.xfader.a, dj.xfader.b = instA.name, instB.name
+	dj.mu.Unlock()
+	dj.applyCrossfader()
+	log.Printf("🎚️  Crossfader: A = '%s', B = '%s'.", instA.name, instB.name)
+	return nil
+}
+
+// ClearDecks takes both instruments off the crossfader at full level.
+func (dj *DJMixer) ClearDecks() error {
+	if !dj.releaseDecks() {
+		return errorf(ErrInvalidState, "crossfader não tem instrumentos atribuídos")
+	}
+	log.Println("🎚️  Crossfader desligado.")
+	return nil
+}
+
+// releaseDecks unassigns the decks, restoring their level, and reports
+// whether any were assigned.
+func (dj *DJMixer) releaseDecks() bool {
+	dj.mu.Lock()
+	a, b := dj.instruments[dj.xfader.a], dj.instruments[dj.xfader.b]
+	assigned := dj.xfader.a != ""
+	dj.xfader.a, dj.xfader.b = "", ""
+	dj.mu.Unlock()
+	speaker.Lock()
+	for _, inst := range []*Instrument{a, b} {
+		if inst != nil {
+			inst.xfade.Gain = 0
+		}
+	}
+	speaker.Unlock()
+	return assigned
+}
+
+// SetCrossfader moves the crossfader to pos, from 0 (deck A) to 1 (deck B).
+func (dj *DJMixer) SetCrossfader(pos float64) error {
+	if !within(pos, 0, 1) {
+		return errorf(ErrOutOfRange, "posição do crossfader %.2f está fora do intervalo [0, 1]", pos)
+	}
+	dj.mu.Lock()
+	if dj.xfader.a == "" {
+		dj.mu.Unlock()
+		return errorf(ErrInvalidState, "crossfader não tem instrumentos atribuídos (use 'xfader assign <a> <b>')")
+	}
+	dj.xfader.pos = pos
+	dj.mu.Unlock()
+	dj.applyCrossfader()
+	return nil
+}
+
+// SetCrossfaderCurve switches between the smooth and cut curves.
+func (dj *DJMixer) SetCrossfaderCurve(c XfaderCurve) {
+	dj.mu.Lock()
+	dj.xfader.curve = c
+	dj.mu.Unlock()
+	dj.applyCrossfader()
+	log.Printf("🎚️  Curva do crossfader: %s.", c)
+}
+
+// Crossfader returns the crossfader state.
+func (dj *DJMixer) Crossfader() crossfader {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	return dj.xfader
+}
+
+// applyCrossfader sets the deck levels for the current position and curve.
+func (dj *DJMixer) applyCrossfader() {
+	dj.mu.RLock()
+	x := dj.xfader
+	a, b := dj.instruments[x.a], dj.instruments[x.b]
+	dj.mu.RUnlock()
+	gainA, gainB := x.gains()
+	speaker.Lock()
+	defer speaker.Unlock()
+	// effects.Gain multiplies by 1+Gain.
+	if a != nil {
+		a.xfade.Gain = gainA - 1
+	}
+	if b != nil {
+		b.xfade.Gain = gainB - 1
+	}
+}
+
+func printCrossfader(out io.Writer, x crossfader) {
+	if x.a == "" {
+		fmt.Fprintf(out, "Crossfader sem instrumentos (curva %s).\n", x.curve)
+		return
+	}
+	gainA, gainB := x.gains()
+	fmt.Fprintf(out, "Crossfader: A '%s' %.0f%% | B '%s' %.0f%% (posição %.2f, curva %s)\n", x.a, gainA*100, x.b, gainB*100, x.pos, x.curve)
+}
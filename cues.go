@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// MaxHotCues is the number of hot-cue slots per instrument, numbered from 1.
+const MaxHotCues = 8
+
+// readWAVCues returns the sample frames of the cue points in a WAV file's
+// "cue " chunk, in order. wav.Decode skips that chunk, so the RIFF chunks are
+// walked here. A file without one has no cues.
+func readWAVCues(path string) ([]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var header [12]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil {
+		return nil, err
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%s não é um arquivo RIFF/WAVE", path)
+	}
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(f, chunk[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		if string(chunk[0:4]) != "cue " {
+			// Chunks are padded to an even length.
+			if _, err := f.Seek(size+size%2, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(f, data); err != nil {
+			return nil, fmt.Errorf("chunk de cues truncado em %s: %w", path, err)
+		}
+		return parseCueChunk(data), nil
+	}
+}
+
+// parseCueChunk reads the sample offsets out of a "cue " chunk: a point count
+// followed by 24-byte points whose last field is the sample offset.
+func parseCueChunk(data []byte) []int {
+	if len(data) < 4 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint32(data[0:4]))
+	var cues []int
+	for k := 0; k < count && 4+24*(k+1) <= len(data); k++ {
+		point := data[4+24*k : 4+24*(k+1)]
+		cues = append(cues, int(binary.LittleEndian.Uint32(point[20:24])))
+	}
+	sort.Ints(cues)
+	return cues
+}
+
+// loadFileCues fills the hot-cue slots from the cue points embedded in the
+// instrument's file, the earliest first, on load and on reload. Slots the file
+// doesn't fill keep the cues set by hand, unless the file is now too short for
+// them. The caller must hold i.mu.
+func (i *Instrument) loadFileCues() {
+	if i.path == "" {
+		return
+	}
+	cues, err := readWAVCues(i.path)
+	if err != nil {
+		log.Printf("⚠️  Cues de '%s' não foram lidos: %v", i.name, err)
+		return
+	}
+	if i.cues == nil {
+		i.cues = make(map[int]int)
+	}
+	for slot, sample := range i.cues {
+		if sample >= i.streamer.Len() {
+			delete(i.cues, slot)
+		}
+	}
+	read := 0
+	for _, sample := range cues {
+		if read == MaxHotCues {
+			break
+		}
+		if sample < i.streamer.Len() {
+			read++
+			i.cues[read] = sample
+		}
+	}
+	if read > 0 {
+		log.Printf("📍 %d cue(s) lido(s) do arquivo de '%s'.", read, i.name)
+	}
+}
+
+func validateCueSlot(slot int) error {
+	if slot < 1 || slot > MaxHotCues {
+		return errorf(ErrOutOfRange, "cue %d está fora do intervalo [1, %d]", slot, MaxHotCues)
+	}
+	return nil
+}
+
+// SetCue stores the sample being heard in a hot-cue slot.
+func (i *Instrument) SetCue(slot int) error {
+	if err := validateCueSlot(slot); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	heard, _ := i.heardSource()
+	speaker.Unlock()
+	if i.cues == nil {
+		i.cues = make(map[int]int)
+	}
+	i.cues[slot] = int(heard)
+	log.Printf("📍 Cue %d de '%s' em %s.", slot, i.name, i.format.SampleRate.D(int(heard)).Round(time.Millisecond))
+	return nil
+}
+
+// JumpToCue starts playing from a hot cue.
+func (i *Instrument) JumpToCue(slot int) error {
+	if err := validateCueSlot(slot); err != nil {
+		return err
+	}
+	i.mu.RLock()
+	sample, ok := i.cues[slot]
+	i.mu.RUnlock()
+	if !ok {
+		return errorf(ErrInvalidState, "cue %d de '%s' está vazio", slot, i.name)
+	}
+	return i.PlayFrom(i.format.SampleRate.D(sample))
+}
+
+// ClearCue empties a hot-cue slot.
+func (i *Instrument) ClearCue(slot int) error {
+	if err := validateCueSlot(slot); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	delete(i.cues, slot)
+	i.mu.Unlock()
+	log.Printf("📍 Cue %d de '%s' apagado.", slot, i.name)
+	return nil
+}
+
+// Cues returns the filled hot-cue slots and their positions.
+func (i *Instrument) Cues() map[int]time.Duration {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	cues := make(map[int]time.Duration, len(i.cues))
+	for slot, sample := range i.cues {
+		cues[slot] = i.format.SampleRate.D(sample)
+	}
+	return cues
+}
+
+// cueSummary lists the filled slots in order, e.g. "1 0.00s, 3 12.50s".
+func cueSummary(cues map[int]time.Duration) string {
+	var parts []string
+	for slot := 1; slot <= MaxHotCues; slot++ {
+		if pos, ok := cues[slot]; ok {
+			parts = append(parts, fmt.Sprintf("%d %.2fs", slot, pos.Seconds()))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func cueSeconds(cues map[int]time.Duration) map[int]float64 {
+	if len(cues) == 0 {
+		return nil
+	}
+	secs := make(map[int]float64, len(cues))
+	for slot, pos := range cues {
+		secs[slot] = pos.Seconds()
+	}
+	return secs
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+)
+
+// fileHash returns the SHA-256 of the file contents, in hex.
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("falha ao abrir arquivo %s: %w", path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("falha ao ler arquivo %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// findByHash returns the loaded instrument whose file has the given hash; the
+// caller must hold dj.mu.
+func (dj *DJMixer) findByHash(hash string) (*Instrument, bool) {
+	for _, inst := range dj.instruments {
+		if inst.hash != "" && inst.hash == hash {
+			return inst, true
+		}
+	}
+	return nil, false
+}
+
+// aliasDuplicate makes name an alias of the loaded instrument with the same
+// hash, if there is one, and reports whether it did; the caller must hold
+// dj.mu.
+func (dj *DJMixer) aliasDuplicate(name, hash string) bool {
+	orig, dup := dj.findByHash(hash)
+	if !dup {
+		return false
+	}
+	if dj.instrumentAliases == nil {
+		dj.instrumentAliases = make(map[string]string)
+	}
+	dj.instrumentAliases[name] = orig.name
+	log.Printf("♊ '%s' tem o mesmo conteúdo de '%s'; usando '%s' como apelido em vez de recarregar.", name, orig.name, name)
+	return true
+}
+
+// resolveName follows an instrument alias created for a duplicate file; the
+// caller must hold dj.mu.
+func (dj *DJMixer) resolveName(name string) string {
+	if target, ok := dj.instrumentAliases[name]; ok {
+		return target
+	}
+	return name
+}
+
+// nameTaken reports whether name is an instrument or an alias; an instrument
+// loaded under an alias's name could never be reached, since resolveName
+// prefers the alias. The caller must hold dj.mu.
+func (dj *DJMixer) nameTaken(name string) bool {
+	_, inst := dj.instruments[name]
+	_, alias := dj.instrumentAliases[name]
+	return inst || alias
+}
+
+// InstrumentAliases returns the duplicate-file aliases as (alias, instrument)
+// pairs, sorted by alias.
+func (dj *DJMixer) InstrumentAliases() [][2]string {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	pairs := make([][2]string, 0, len(dj.instrumentAliases))
+	for alias, target := range dj.instrumentAliases {
+		pairs = append(pairs, [2]string{alias, target})
+	}
+	sort.Slice(pairs, func(a, b int) bool { return pairs[a][0] < pairs[b][0] })
+	return pairs
+}
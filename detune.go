@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+	"math"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// MaxDetune bounds the fine pitch offset, in cents; past a semitone, speed is
+// the right tool.
+const MaxDetune = 100.0
+
+// resampleRatio combines speed, fine tuning and the file/output rate
+// conversion into the ratio the resampler runs at. The caller must hold i.mu.
+func (i *Instrument) resampleRatio() float64 {
+	return i.speedRatio * math.Pow(2, i.detune/1200) * i.rateRatio
+}
+
+// SetDetune nudges the pitch by cents, clamped to ±MaxDetune, independently of
+// the speed ratio, so two tracks at the same tempo can be brought into tune.
+// Like speed changes, it also moves the tempo slightly, and it is applied the
+// same way, keeping the phase or gliding.
+func (i *Instrument) SetDetune(cents float64) {
+	if !within(cents, -MaxDetune, MaxDetune) {
+		cents = math.Copysign(MaxDetune, cents)
+		log.Printf("⚠️  Detune limitado a %+.0f cents.", cents)
+	}
+	i.mu.Lock()
+	i.detune = cents
+	speaker.Lock()
+	i.retime()
+	speaker.Unlock()
+	i.mu.Unlock()
+	log.Printf("🎵 Afinação de '%s' ajustada em %+.1f cents.", i.name, cents)
+}
+
+// Detune returns the fine pitch offset in cents.
+func (i *Instrument) Detune() float64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.detune
+}
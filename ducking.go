@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+const (
+	DefaultDuckRelease = 0.25 // seconds
+	MaxDuckAmount      = 4.0
+)
+
+// ducker lowers an instrument's level while its sidechain source is loud. The
+// source's peak drives an envelope with instant attack and exponential release;
+// the gain reduction is amount*envelope in the same base-2 units as the volume
+// fader, so an amount of 1 halves the level at full source level.
+type ducker struct {
+	streamer   beep.Streamer
+	sampleRate beep.SampleRate
+	source     *levelMeter
+	sourceName string
+	amount     float64
+	release    float64 // seconds
+	env        float64
+}
+
+func (d *ducker) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = d.streamer.Stream(samples)
+	if d.source == nil || d.amount <= 0 {
+		return n, ok
+	}
+	decay := math.Exp(-float64(n) / (d.release * float64(d.sampleRate)))
+	d.env = math.Max(math.Min(d.source.peak, 1), d.env*decay)
+	gain := math.Pow(2, -d.amount*d.env)
+	for i := range samples[:n] {
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+	}
+	return n, ok
+}
+
+func (d *ducker) Err() error {
+	return d.streamer.Err()
+}
+
+// Duck makes every target pump against the level of source.
+func (dj *DJMixer) Duck(sourceName string, targetNames []string, amount, release float64) error {
+	if amount < 0 || amount > MaxDuckAmount {
+		return errorf(ErrOutOfRange, "quantidade de ducking %.2f está fora do intervalo [0, %.2f]", amount, MaxDuckAmount)
+	}
+	if release <= 0 {
+		return fmt.Errorf("tempo de release deve ser positivo")
+	}
+	source, ok := dj.GetInstrument(sourceName)
+	if !ok {
+		return instrumentNotFound(sourceName)
+	}
+	targets := make([]*Instrument, 0, len(targetNames))
+	for _, name := range targetNames {
+		if name == sourceName {
+			return fmt.Errorf("'%s' não pode fazer ducking de si mesmo", name)
+		}
+		inst, ok := dj.GetInstrument(name)
+		if !ok {
+			return instrumentNotFound(name)
+		}
+		targets = append(targets, inst)
+	}
+	speaker.Lock()
+	for _, inst := range targets {
+		inst.ducker.source = source.meter
+		inst.ducker.sourceName = source.name
+		inst.ducker.amount = amount
+		inst.ducker.release = release
+		inst.ducker.env = 0
+	}
+	speaker.Unlock()
+	log.Printf("🦆 Ducking de %s acionado por '%s' (quantidade %.2f, release %.0f ms).", strings.Join(targetNames, ", "), sourceName, amount, release*1000)
+	return nil
+}
+
+// Unduck removes every ducking route driven by source.
+func (dj *DJMixer) Unduck(sourceName string) error {
+	if _, ok := dj.GetInstrument(sourceName); !ok {
+		return instrumentNotFound(sourceName)
+	}
+	instruments := dj.GetAllInstrumentsSorted()
+	speaker.Lock()
+	for _, inst := range instruments {
+		if inst.ducker.sourceName == sourceName {
+			inst.ducker.source = nil
+			inst.ducker.sourceName = ""
+			inst.ducker.env = 0
+		}
+	}
+	speaker.Unlock()
+	log.Printf("🦆 Ducking acionado por '%s' removido.", sourceName)
+	return nil
+}
+
+// handleDuckCommand parses "duck <source> <targets...> <amount> [releaseMs]"
+// and "duck <source> off". Targets are the leading arguments that name loaded
+// instruments; the first argument that doesn't starts the numeric parameters.
+func handleDuckCommand(dj *DJMixer, args []string) error {
+	usage := fmt.Errorf("uso: duck <fonte> <alvos...> <quantidade> [releaseMs] | duck <fonte> off")
+	if len(args) < 2 {
+		return usage
+	}
+	source := args[0]
+	if args[1] == "off" {
+		return dj.Unduck(source)
+	}
+	rest := args[1:]
+	var targets []string
+	for len(rest) > 0 {
+		if _, ok := dj.GetInstrument(rest[0]); !ok {
+			break
+		}
+		targets = append(targets, rest[0])
+		rest = rest[1:]
+	}
+	if len(targets) == 0 || len(rest) == 0 || len(rest) > 2 {
+		return usage
+	}
+	amount, err := parseFloatArg("quantidade de ducking", rest[0])
+	if err != nil {
+		return err
+	}
+	release := DefaultDuckRelease
+	if len(rest) == 2 {
+		ms, err := parseFloatArg("release do ducking", rest[1])
+		if err != nil {
+			return err
+		}
+		release = ms / 1000
+	}
+	return dj.Duck(source, targets, amount, release)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DumpScript returns the commands that bring the mix back to its current
+// state: master tempo, level and EQ, then for every instrument a reset
+// followed by whatever differs from the defaults and its transport state. The
+// result is plain text that can be edited and fed back on standard input,
+// unlike the JSON of a saved session. Playheads are not kept: instruments
+// start again from the top.
+func (dj *DJMixer) DumpScript() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# go-dj: estado em %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "masterbpm %s\n", scriptNum(dj.MasterBPM()))
+	fmt.Fprintf(&b, "mastervol %s\n", scriptNum(dj.MasterVolume()))
+	if g := dj.MasterEQ(); g != [3]float64{} {
+		fmt.Fprintf(&b, "mastereq %s %s %s\n", scriptNum(g[EQLow]), scriptNum(g[EQMid]), scriptNum(g[EQHigh]))
+	}
+	for _, inst := range dj.GetAllInstrumentsSorted() {
+		b.WriteString("\n")
+		inst.dumpScript(&b)
+	}
+	return b.String()
+}
+
+// dumpScript writes the commands for one instrument.
+func (i *Instrument) dumpScript(w io.Writer) {
+	snap := i.Snapshot()
+	fx := snap.Effects
+	n := i.name
+	fmt.Fprintf(w, "reset %s\n", n)
+	if snap.Volume != DefaultVolume {
+		fmt.Fprintf(w, "volume %s %s\n", n, scriptNum(snap.Volume))
+	}
+	// reset goes back to the original tempo, not the master's.
+	fmt.Fprintf(w, "bpm %s %s\n", n, scriptNum(BaseBPM*snap.SpeedRatio))
+	if d := i.Detune(); d != 0 {
+		fmt.Fprintf(w, "detune %s %s\n", n, scriptNum(d))
+	}
+	if c := i.LoopCount(); c > 0 {
+		fmt.Fprintf(w, "loop %s %d\n", n, c)
+	}
+	if o := i.Offset(); o != 0 {
+		fmt.Fprintf(w, "offset %s %s\n", n, scriptNum(o))
+	}
+	if fx.Trim != 0 {
+		fmt.Fprintf(w, "trim %s %s\n", n, scriptNum(fx.Trim))
+	}
+	if f := fx.Flanger; f.RateHz > 0 {
+		fmt.Fprintf(w, "flanger %s %s %s %s\n", n, scriptNum(f.RateHz), scriptNum(f.Depth), scriptNum(f.Feedback))
+	}
+	if c := fx.Chorus; c.RateHz > 0 {
+		fmt.Fprintf(w, "chorus %s %s %s %d\n", n, scriptNum(c.RateHz), scriptNum(c.Depth), c.Voices)
+	}
+	if fx.Drive != 0 {
+		fmt.Fprintf(w, "drive %s %s\n", n, scriptNum(fx.Drive))
+	}
+	if fx.LowPass > 0 {
+		fmt.Fprintf(w, "lowpass %s %s\n", n, scriptNum(fx.LowPass))
+	}
+	if fx.Width != 1 {
+		fmt.Fprintf(w, "width %s %s\n", n, scriptNum(fx.Width))
+	}
+	if fx.SwapLR {
+		fmt.Fprintf(w, "swaplr %s on\n", n)
+	}
+	if fx.PhaseInvert {
+		fmt.Fprintf(w, "phaseinvert %s on\n", n)
+	}
+	for c, side := range []string{"left", "right"} {
+		if fx.ChannelGain[c] != 1 {
+			fmt.Fprintf(w, "chanvol %s %s %s\n", n, side, scriptNum(fx.ChannelGain[c]))
+		}
+		if fx.ChannelMute[c] {
+			fmt.Fprintf(w, "chanmute %s %s on\n", n, side)
+		}
+	}
+	// Freeze holds whatever is sounding, so it goes on once the instrument is.
+	switch snap.State {
+	case StatePlaying:
+		fmt.Fprintf(w, "play %s\n", n)
+	case StatePaused:
+		fmt.Fprintf(w, "play %s\npause %s\n", n, n)
+	}
+	if fx.Freeze {
+		fmt.Fprintf(w, "freeze %s on\n", n)
+	}
+}
+
+// scriptNum formats a value for a command, rounded so float noise such as
+// 0.30000000000000004 doesn't end up in the script.
+func scriptNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
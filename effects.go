@@ -0,0 +1,672 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// --- Effect Parameters ---
+
+const (
+	flangerMinDelay    = 0.001 // seconds
+	flangerSweep       = 0.009 // seconds of extra delay at full depth
+	flangerMaxFeedback = 0.9   // keeps the comb filter below self-oscillation
+
+	chorusBaseDelay = 0.020 // seconds
+	chorusSweep     = 0.005 // seconds of extra delay at full depth
+	chorusMaxVoices = 4
+
+	MaxDrive = 10.0
+	MaxWidth = 2.0
+
+	MaxChannelGain = 2.0
+
+	MinTrimDB = -24.0
+	MaxTrimDB = 24.0
+)
+
+// Channel indexes into a [2]float64 frame.
+const (
+	ChannelLeft = iota
+	ChannelRight
+)
+
+var channelNames = [2]string{"esquerdo", "direito"}
+
+// effectSettings holds the parameters of an instrument's effect chain, so the
+// whole chain can be snapshotted and restored at once.
+type effectSettings struct {
+	// Trim is the input gain in dB applied before every other stage.
+	Trim    float64       `json:"trimDb"`
+	Flanger flangerParams `json:"flanger"`
+	Chorus  chorusParams  `json:"chorus"`
+	Freeze  bool          `json:"freeze"`
+	Drive   float64       `json:"drive"`
+	// LowPass is the static low-pass cutoff in Hz; 0 is off.
+	LowPass float64 `json:"lowPassHz"`
+	SwapLR  bool    `json:"swapLR"`
+	// PhaseInvert flips the polarity of both channels.
+	PhaseInvert bool    `json:"phaseInvert"`
+	Width       float64 `json:"width"`
+	// ChannelGain holds linear per-channel gains (left, right); 1 is unity.
+	ChannelGain [2]float64 `json:"channelGain"`
+	ChannelMute [2]bool    `json:"channelMute"`
+}
+
+// defaultEffectSettings returns the neutral chain: every effect bypassed.
+func defaultEffectSettings() effectSettings {
+	return effectSettings{Width: 1, ChannelGain: [2]float64{1, 1}}
+}
+
+// within reports whether v lies in [lo, hi]; NaN never does.
+func within(v, lo, hi float64) bool {
+	return v >= lo && v <= hi
+}
+
+// validate checks settings that come from outside, such as a presets or
+// session file, against the bounds the setters enforce. They are installed as
+// they are, and some values would misbehave on the audio thread: more chorus
+// voices than the chorus has LFOs, or a feedback that runs away.
+func (e effectSettings) validate() error {
+	switch {
+	case !within(e.Trim, MinTrimDB, MaxTrimDB):
+		return errorf(ErrOutOfRange, "trim %.1f dB está fora do intervalo [%.1f, %.1f]", e.Trim, MinTrimDB, MaxTrimDB)
+	case !within(e.Flanger.RateHz, 0, math.MaxFloat64):
+		return fmt.Errorf("taxa do flanger %.2f Hz inválida", e.Flanger.RateHz)
+	case !within(e.Flanger.Depth, 0, 1):
+		return errorf(ErrOutOfRange, "profundidade do flanger %.2f está fora do intervalo [0, 1]", e.Flanger.Depth)
+	case !within(e.Flanger.Feedback, -flangerMaxFeedback, flangerMaxFeedback):
+		return errorf(ErrOutOfRange, "realimentação do flanger %.2f está fora do intervalo [%.1f, %.1f]", e.Flanger.Feedback, -flangerMaxFeedback, flangerMaxFeedback)
+	case !within(e.Chorus.RateHz, 0, math.MaxFloat64):
+		return fmt.Errorf("taxa do chorus %.2f Hz inválida", e.Chorus.RateHz)
+	case !within(e.Chorus.Depth, 0, 1):
+		return errorf(ErrOutOfRange, "profundidade do chorus %.2f está fora do intervalo [0, 1]", e.Chorus.Depth)
+	case e.Chorus.Voices < 0 || e.Chorus.Voices > chorusMaxVoices || (e.Chorus.RateHz > 0 && e.Chorus.Voices == 0):
+		return errorf(ErrOutOfRange, "número de vozes %d está fora do intervalo [1, %d]", e.Chorus.Voices, chorusMaxVoices)
+	case !within(e.Drive, 0, MaxDrive):
+		return errorf(ErrOutOfRange, "drive %.1f está fora do intervalo [0, %.0f]", e.Drive, MaxDrive)
+	case e.LowPass != 0 && !within(e.LowPass, MinLowPassHz, MaxLowPassHz):
+		return errorf(ErrOutOfRange, "corte %.0f Hz está fora do intervalo [%.0f, %.0f]", e.LowPass, MinLowPassHz, MaxLowPassHz)
+	case !within(e.Width, 0, MaxWidth):
+		return errorf(ErrOutOfRange, "largura estéreo %.2f está fora do intervalo [0, %.2f]", e.Width, MaxWidth)
+	}
+	for c, gain := range e.ChannelGain {
+		if !within(gain, 0, MaxChannelGain) {
+			return errorf(ErrOutOfRange, "ganho do canal %s %.2f está fora do intervalo [0, %.2f]", channelNames[c], gain, MaxChannelGain)
+		}
+	}
+	return nil
+}
+
+// summary lists the active effects for compact display, e.g. "[flanger drive 2.0]".
+func (e effectSettings) summary() string {
+	var active []string
+	if e.Trim != 0 {
+		active = append(active, fmt.Sprintf("trim %+.1fdB", e.Trim))
+	}
+	if e.Flanger.RateHz > 0 {
+		active = append(active, "flanger")
+	}
+	if e.Chorus.RateHz > 0 {
+		active = append(active, "chorus")
+	}
+	if e.Freeze {
+		active = append(active, "freeze")
+	}
+	if e.Drive > 0 {
+		active = append(active, fmt.Sprintf("drive %.1f", e.Drive))
+	}
+	if e.LowPass > 0 {
+		active = append(active, fmt.Sprintf("lowpass %.0fHz", e.LowPass))
+	}
+	if e.SwapLR {
+		active = append(active, "swap L/R")
+	}
+	if e.PhaseInvert {
+		active = append(active, "invert")
+	}
+	if e.Width != 1 {
+		active = append(active, fmt.Sprintf("width %.2f", e.Width))
+	}
+	for c, name := range [2]string{"L", "R"} {
+		if e.ChannelMute[c] {
+			active = append(active, "mute "+name)
+		} else if e.ChannelGain[c] != 1 {
+			active = append(active, fmt.Sprintf("%s %.2f", name, e.ChannelGain[c]))
+		}
+	}
+	if len(active) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(active, " ") + "]"
+}
+
+type flangerParams struct {
+	RateHz   float64 `json:"rateHz"`
+	Depth    float64 `json:"depth"`
+	Feedback float64 `json:"feedback"`
+}
+
+type chorusParams struct {
+	RateHz float64 `json:"rateHz"`
+	Depth  float64 `json:"depth"`
+	Voices int     `json:"voices"`
+}
+
+// --- Flanger ---
+
+// flanger mixes the signal with a copy delayed by a few milliseconds, the delay
+// time being swept by a sine LFO. A rate of 0 bypasses it.
+type flanger struct {
+	streamer   beep.Streamer
+	sampleRate beep.SampleRate
+	params     flangerParams
+	buf        [][2]float64
+	pos        int
+	phase      float64
+}
+
+func newFlanger(s beep.Streamer, sr beep.SampleRate) *flanger {
+	size := int((flangerMinDelay+flangerSweep)*float64(sr)) + 2
+	return &flanger{streamer: s, sampleRate: sr, buf: make([][2]float64, size)}
+}
+
+// setSampleRate resizes the delay line for sr.
+func (f *flanger) setSampleRate(sr beep.SampleRate) {
+	size := int((flangerMinDelay+flangerSweep)*float64(sr)) + 2
+	f.sampleRate, f.buf = sr, make([][2]float64, size)
+	f.reset()
+}
+
+func (f *flanger) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = f.streamer.Stream(samples)
+	if f.params.RateHz <= 0 {
+		return n, ok
+	}
+	sr := float64(f.sampleRate)
+	step := 2 * math.Pi * f.params.RateHz / sr
+	for i := range samples[:n] {
+		sweep := (1 + math.Sin(f.phase)) / 2
+		delay := (flangerMinDelay + flangerSweep*f.params.Depth*sweep) * sr
+		for c := range samples[i] {
+			dry := samples[i][c]
+			wet := readDelay(f.buf, f.pos, delay, c)
+			f.buf[f.pos][c] = dry + wet*f.params.Feedback
+			samples[i][c] = (dry + wet) / 2
+		}
+		f.pos = (f.pos + 1) % len(f.buf)
+		f.phase = math.Mod(f.phase+step, 2*math.Pi)
+	}
+	return n, ok
+}
+
+func (f *flanger) Err() error {
+	return f.streamer.Err()
+}
+
+// reset clears the delay line so no stale audio is heard after a seek.
+func (f *flanger) reset() {
+	for i := range f.buf {
+		f.buf[i] = [2]float64{}
+	}
+	f.pos = 0
+	f.phase = 0
+}
+
+// --- Chorus ---
+
+// chorus sums several delayed copies of the signal, each swept by its own LFO
+// with a slightly different rate and phase, to thicken the sound. A rate of 0
+// bypasses it.
+type chorus struct {
+	streamer   beep.Streamer
+	sampleRate beep.SampleRate
+	params     chorusParams
+	buf        [][2]float64
+	pos        int
+	phases     [chorusMaxVoices]float64
+}
+
+func newChorus(s beep.Streamer, sr beep.SampleRate) *chorus {
+	size := int((chorusBaseDelay+chorusSweep)*float64(sr)) + 2
+	c := &chorus{streamer: s, sampleRate: sr, buf: make([][2]float64, size)}
+	c.reset()
+	return c
+}
+
+// setSampleRate resizes the delay line for sr.
+func (ch *chorus) setSampleRate(sr beep.SampleRate) {
+	size := int((chorusBaseDelay+chorusSweep)*float64(sr)) + 2
+	ch.sampleRate, ch.buf = sr, make([][2]float64, size)
+	ch.reset()
+}
+
+func (ch *chorus) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = ch.streamer.Stream(samples)
+	voices := ch.params.Voices
+	if ch.params.RateHz <= 0 || voices < 1 {
+		return n, ok
+	}
+	sr := float64(ch.sampleRate)
+	for i := range samples[:n] {
+		ch.buf[ch.pos] = samples[i]
+		var wet [2]float64
+		for v := 0; v < voices; v++ {
+			sweep := (1 + math.Sin(ch.phases[v])) / 2
+			delay := (chorusBaseDelay + chorusSweep*ch.params.Depth*sweep) * sr
+			for c := range wet {
+				wet[c] += readDelay(ch.buf, ch.pos, delay, c)
+			}
+			// Each voice runs slightly faster than the previous one so they drift apart.
+			rate := ch.params.RateHz * (1 + 0.13*float64(v))
+			ch.phases[v] = math.Mod(ch.phases[v]+2*math.Pi*rate/sr, 2*math.Pi)
+		}
+		for c := range samples[i] {
+			samples[i][c] = (samples[i][c] + wet[c]/float64(voices)) / 2
+		}
+		ch.pos = (ch.pos + 1) % len(ch.buf)
+	}
+	return n, ok
+}
+
+func (ch *chorus) Err() error {
+	return ch.streamer.Err()
+}
+
+// reset clears the delay line and spreads the voices' LFO phases evenly.
+func (ch *chorus) reset() {
+	for i := range ch.buf {
+		ch.buf[i] = [2]float64{}
+	}
+	ch.pos = 0
+	for v := range ch.phases {
+		ch.phases[v] = 2 * math.Pi * float64(v) / chorusMaxVoices
+	}
+}
+
+// --- Drive ---
+
+// drive is a soft-clipping waveshaper: the signal is amplified by 1+amount and
+// folded through tanh, normalized so a full-scale input stays at full scale.
+// An amount of 0 leaves the signal clean.
+type drive struct {
+	streamer beep.Streamer
+	amount   float64
+}
+
+func (d *drive) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = d.streamer.Stream(samples)
+	if d.amount <= 0 {
+		return n, ok
+	}
+	gain := 1 + d.amount
+	norm := math.Tanh(gain)
+	for i := range samples[:n] {
+		for c := range samples[i] {
+			samples[i][c] = math.Tanh(samples[i][c]*gain) / norm
+		}
+	}
+	return n, ok
+}
+
+func (d *drive) Err() error {
+	return d.streamer.Err()
+}
+
+// --- Channel Swap ---
+
+// channelSwap exchanges the left and right channels when enabled.
+type channelSwap struct {
+	streamer beep.Streamer
+	enabled  bool
+}
+
+func (s *channelSwap) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = s.streamer.Stream(samples)
+	if s.enabled {
+		for i := range samples[:n] {
+			samples[i][0], samples[i][1] = samples[i][1], samples[i][0]
+		}
+	}
+	return n, ok
+}
+
+func (s *channelSwap) Err() error {
+	return s.streamer.Err()
+}
+
+// --- Phase Invert ---
+
+// phaseInvert flips the polarity of the signal when enabled, to fix phase
+// cancellation between layered sounds. It works sample by sample, so it adds
+// no latency.
+type phaseInvert struct {
+	streamer beep.Streamer
+	enabled  bool
+}
+
+func (p *phaseInvert) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = p.streamer.Stream(samples)
+	if p.enabled {
+		for i := range samples[:n] {
+			samples[i][0], samples[i][1] = -samples[i][0], -samples[i][1]
+		}
+	}
+	return n, ok
+}
+
+func (p *phaseInvert) Err() error {
+	return p.streamer.Err()
+}
+
+// --- Stereo Width ---
+
+// stereoWidth scales the side (L-R) component of a stereo signal: 0 collapses
+// it to mono, 1 leaves it unchanged and values above 1 widen the image.
+type stereoWidth struct {
+	streamer beep.Streamer
+	factor   float64
+}
+
+func (w *stereoWidth) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = w.streamer.Stream(samples)
+	if w.factor == 1 {
+		return n, ok
+	}
+	for i := range samples[:n] {
+		mid := (samples[i][0] + samples[i][1]) / 2
+		side := (samples[i][0] - samples[i][1]) / 2 * w.factor
+		samples[i][0] = mid + side
+		samples[i][1] = mid - side
+	}
+	return n, ok
+}
+
+func (w *stereoWidth) Err() error {
+	return w.streamer.Err()
+}
+
+// --- Channel Gain ---
+
+// channelGain applies independent linear gains to the left and right channels.
+// Mono files are decoded into both channels, so they can be split the same way.
+type channelGain struct {
+	streamer beep.Streamer
+	gains    [2]float64
+	muted    [2]bool
+}
+
+func (g *channelGain) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = g.streamer.Stream(samples)
+	var eff [2]float64
+	for c := range eff {
+		if !g.muted[c] {
+			eff[c] = g.gains[c]
+		}
+	}
+	if eff == [2]float64{1, 1} {
+		return n, ok
+	}
+	for i := range samples[:n] {
+		samples[i][0] *= eff[0]
+		samples[i][1] *= eff[1]
+	}
+	return n, ok
+}
+
+func (g *channelGain) Err() error {
+	return g.streamer.Err()
+}
+
+// readDelay reads channel c from a circular delay line, delay samples behind
+// the write position, interpolating linearly between neighbouring samples.
+func readDelay(buf [][2]float64, pos int, delay float64, c int) float64 {
+	size := len(buf)
+	whole := int(delay)
+	frac := delay - float64(whole)
+	a := buf[((pos-whole)%size+size)%size][c]
+	b := buf[((pos-whole-1)%size+size)%size][c]
+	return a + (b-a)*frac
+}
+
+// --- Instrument Effect Methods ---
+
+func (i *Instrument) SetFlanger(rateHz, depth, feedback float64) error {
+	if rateHz < 0 {
+		return fmt.Errorf("taxa do flanger %.2f Hz não pode ser negativa", rateHz)
+	}
+	if depth < 0 || depth > 1 {
+		return errorf(ErrOutOfRange, "profundidade do flanger %.2f está fora do intervalo [0, 1]", depth)
+	}
+	feedback = math.Max(-flangerMaxFeedback, math.Min(flangerMaxFeedback, feedback))
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.flanger.params = flangerParams{RateHz: rateHz, Depth: depth, Feedback: feedback}
+	if rateHz == 0 {
+		i.flanger.reset()
+	}
+	speaker.Unlock()
+	if rateHz == 0 {
+		log.Printf("🌀 Flanger de '%s' desligado.", i.name)
+	} else {
+		log.Printf("🌀 Flanger de '%s': %.2f Hz, profundidade %.2f, realimentação %.2f.", i.name, rateHz, depth, feedback)
+	}
+	return nil
+}
+
+func (i *Instrument) SetChorus(rateHz, depth float64, voices int) error {
+	if rateHz < 0 {
+		return fmt.Errorf("taxa do chorus %.2f Hz não pode ser negativa", rateHz)
+	}
+	if depth < 0 || depth > 1 {
+		return errorf(ErrOutOfRange, "profundidade do chorus %.2f está fora do intervalo [0, 1]", depth)
+	}
+	if voices < 1 || voices > chorusMaxVoices {
+		return errorf(ErrOutOfRange, "número de vozes %d está fora do intervalo [1, %d]", voices, chorusMaxVoices)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.chorus.params = chorusParams{RateHz: rateHz, Depth: depth, Voices: voices}
+	if rateHz == 0 {
+		i.chorus.reset()
+	}
+	speaker.Unlock()
+	if rateHz == 0 {
+		log.Printf("🎶 Chorus de '%s' desligado.", i.name)
+	} else {
+		log.Printf("🎶 Chorus de '%s': %.2f Hz, profundidade %.2f, %d vozes.", i.name, rateHz, depth, voices)
+	}
+	return nil
+}
+
+// SetDrive sets the distortion amount, clamped to [0, MaxDrive].
+func (i *Instrument) SetDrive(amount float64) error {
+	amount = math.Max(0, math.Min(MaxDrive, amount))
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.drive.amount = amount
+	speaker.Unlock()
+	if amount == 0 {
+		log.Printf("🔥 Drive de '%s' desligado.", i.name)
+	} else {
+		log.Printf("🔥 Drive de '%s' definido para %.1f.", i.name, amount)
+	}
+	return nil
+}
+
+// SetSwapLR swaps the left and right channels, to fix files recorded with
+// them reversed. Mono sources play the same either way.
+func (i *Instrument) SetSwapLR(swap bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.swap.enabled = swap
+	speaker.Unlock()
+	if i.format.NumChannels < 2 {
+		log.Printf("ℹ️  '%s' é mono; inverter os canais não altera o som.", i.name)
+	}
+	if swap {
+		log.Printf("🔀 Canais de '%s' invertidos.", i.name)
+	} else {
+		log.Printf("🔀 Canais de '%s' na ordem original.", i.name)
+	}
+}
+
+// SetPhaseInvert flips the polarity of both channels.
+func (i *Instrument) SetPhaseInvert(on bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.invert.enabled = on
+	speaker.Unlock()
+	if on {
+		log.Printf("🔃 Fase de '%s' invertida.", i.name)
+	} else {
+		log.Printf("🔃 Fase de '%s' normal.", i.name)
+	}
+}
+
+// SetWidth sets the stereo width. Mono sources have no side signal, so they
+// are rejected instead of silently ignoring the setting.
+func (i *Instrument) SetWidth(factor float64) error {
+	if factor < 0 || factor > MaxWidth {
+		return errorf(ErrOutOfRange, "largura estéreo %.2f está fora do intervalo [0, %.2f]", factor, MaxWidth)
+	}
+	if i.format.NumChannels < 2 {
+		return fmt.Errorf("instrumento '%s' é mono; a largura estéreo não se aplica", i.name)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.width.factor = factor
+	speaker.Unlock()
+	log.Printf("↔️  Largura estéreo de '%s' definida para %.2f.", i.name, factor)
+	return nil
+}
+
+func (i *Instrument) SetChannelGain(channel int, gain float64) error {
+	if gain < 0 || gain > MaxChannelGain {
+		return errorf(ErrOutOfRange, "ganho de canal %.2f está fora do intervalo [0, %.2f]", gain, MaxChannelGain)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.channels.gains[channel] = gain
+	speaker.Unlock()
+	log.Printf("🎚️  Canal %s de '%s' definido para %.2f.", channelNames[channel], i.name, gain)
+	return nil
+}
+
+func (i *Instrument) SetChannelMute(channel int, muted bool) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.channels.muted[channel] = muted
+	speaker.Unlock()
+	if muted {
+		log.Printf("🔇 Canal %s de '%s' silenciado.", channelNames[channel], i.name)
+	} else {
+		log.Printf("🔊 Canal %s de '%s' reativado.", channelNames[channel], i.name)
+	}
+	return nil
+}
+
+// SetTrim sets the input gain, in dB, at the very front of the chain so the
+// signal can be brought to unity before any processing.
+func (i *Instrument) SetTrim(db float64) error {
+	if !within(db, MinTrimDB, MaxTrimDB) {
+		return errorf(ErrOutOfRange, "trim %.1f dB está fora do intervalo [%.1f, %.1f]", db, MinTrimDB, MaxTrimDB)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.setTrim(db)
+	speaker.Unlock()
+	log.Printf("🎛️  Trim de '%s' definido para %+.1f dB.", i.name, db)
+	return nil
+}
+
+// setTrim converts dB to the linear offset used by effects.Gain; the caller
+// must hold both i.mu and the speaker lock.
+func (i *Instrument) setTrim(db float64) {
+	i.trimDB = db
+	i.trim.Gain = math.Pow(10, db/20) - 1
+}
+
+// Effects returns the current effect chain settings.
+func (i *Instrument) Effects() effectSettings {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.effectSettings()
+}
+
+// effectSettings reads the chain settings; the caller must hold i.mu.
+func (i *Instrument) effectSettings() effectSettings {
+	return effectSettings{
+		Trim:    i.trimDB,
+		Flanger: i.flanger.params,
+		Chorus:  i.chorus.params,
+		Freeze:  i.freezer.frozen,
+		Drive:   i.drive.amount,
+		LowPass: i.lowPass.cutoff,
+		SwapLR:  i.swap.enabled,
+		Width:   i.width.factor,
+
+		PhaseInvert: i.invert.enabled,
+
+		ChannelGain: i.channels.gains,
+		ChannelMute: i.channels.muted,
+	}
+}
+
+// applyEffects sets every effect parameter at once without logging.
+func (i *Instrument) applyEffects(e effectSettings) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	defer speaker.Unlock()
+	i.setEffects(e)
+}
+
+// setEffects writes the chain parameters; the caller must hold both i.mu and
+// the speaker lock.
+func (i *Instrument) setEffects(e effectSettings) {
+	i.setTrim(e.Trim)
+	i.flanger.params = e.Flanger
+	i.chorus.params = e.Chorus
+	i.freezer.frozen = e.Freeze
+	i.drive.amount = e.Drive
+	i.lowPass.setCutoff(e.LowPass)
+	i.swap.enabled = e.SwapLR
+	i.invert.enabled = e.PhaseInvert
+	i.width.factor = e.Width
+	i.channels.gains = e.ChannelGain
+	i.channels.muted = e.ChannelMute
+}
+
+// resetEffectState clears delay lines, LFOs and any beat roll after a seek;
+// the caller must hold the speaker lock.
+func (i *Instrument) resetEffectState() {
+	i.flanger.reset()
+	i.chorus.reset()
+	i.roll.cancel()
+}
+
+// parseChannel maps "left"/"l" and "right"/"r" to a channel index.
+func parseChannel(s string) (int, error) {
+	switch s {
+	case "left", "l":
+		return ChannelLeft, nil
+	case "right", "r":
+		return ChannelRight, nil
+	}
+	return 0, fmt.Errorf("canal inválido: '%s' (use left ou right)", s)
+}
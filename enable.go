@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// SetEnabled disconnects an instrument from the mixer, or reconnects it. A
+// disabled instrument keeps every setting and its playhead, but its chain is
+// not processed at all, unlike a muted one, which still runs silently.
+func (dj *DJMixer) SetEnabled(name string, enabled bool) error {
+	inst, ok := dj.GetInstrument(name)
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if inst.disabled == !enabled {
+		if enabled {
+			return errorf(ErrInvalidState, "instrumento '%s' já está ativado", inst.name)
+		}
+		return errorf(ErrInvalidState, "instrumento '%s' já está desativado", inst.name)
+	}
+	speaker.Lock()
+	if enabled {
+		inst.meter.detached = false
+		// The mixer only lets go of the meter on its next pass; if that has
+		// not happened yet the meter is still in place.
+		if inst.meter.dropped {
+			inst.meter.dropped = false
+			dj.mixer.Add(inst.meter)
+		}
+	} else {
+		inst.meter.detached = true
+	}
+	speaker.Unlock()
+	inst.disabled = !enabled
+	if enabled {
+		log.Printf("🔌 %s reconectado à mixagem.", inst.name)
+	} else {
+		log.Printf("🔌 %s desconectado da mixagem (configurações mantidas).", inst.name)
+	}
+	return nil
+}
+
+// Enabled reports whether the instrument is connected to the mixer.
+func (i *Instrument) Enabled() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return !i.disabled
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+)
+
+// EQ band indexes and the range each band accepts.
+const (
+	EQLow = iota
+	EQMid
+	EQHigh
+
+	MinEQDB = -24.0
+	MaxEQDB = 12.0
+)
+
+// Crossover points of the three bands: a low shelf, a peaking mid and a high
+// shelf, in Hz.
+const (
+	eqLowFreq  = 200.0
+	eqMidFreq  = 1000.0
+	eqHighFreq = 4000.0
+	eqMidQ     = 0.7
+)
+
+var eqBandNames = [3]string{"graves", "médios", "agudos"}
+
+// biquad is a second-order IIR filter with separate state per channel, using
+// the coefficient formulas from Robert Bristow-Johnson's Audio EQ Cookbook.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     [2]float64
+}
+
+// setCoefficients normalizes the cookbook coefficients by a0.
+func (f *biquad) setCoefficients(b0, b1, b2, a0, a1, a2 float64) {
+	f.b0, f.b1, f.b2 = b0/a0, b1/a0, b2/a0
+	f.a1, f.a2 = a1/a0, a2/a0
+}
+
+func (f *biquad) process(c int, x float64) float64 {
+	y := f.b0*x + f.b1*f.x1[c] + f.b2*f.x2[c] - f.a1*f.y1[c] - f.a2*f.y2[c]
+	f.x2[c], f.x1[c] = f.x1[c], x
+	f.y2[c], f.y1[c] = f.y1[c], y
+	return y
+}
+
+func (f *biquad) lowShelf(sr beep.SampleRate, freq, db float64) {
+	a := math.Pow(10, db/40)
+	w0 := 2 * math.Pi * freq / float64(sr)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / 2 * math.Sqrt2 // shelf slope 1
+	sa := 2 * math.Sqrt(a) * alpha
+	f.setCoefficients(
+		a*((a+1)-(a-1)*cos+sa),
+		2*a*((a-1)-(a+1)*cos),
+		a*((a+1)-(a-1)*cos-sa),
+		(a+1)+(a-1)*cos+sa,
+		-2*((a-1)+(a+1)*cos),
+		(a+1)+(a-1)*cos-sa,
+	)
+}
+
+func (f *biquad) highShelf(sr beep.SampleRate, freq, db float64) {
+	a := math.Pow(10, db/40)
+	w0 := 2 * math.Pi * freq / float64(sr)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / 2 * math.Sqrt2 // shelf slope 1
+	sa := 2 * math.Sqrt(a) * alpha
+	f.setCoefficients(
+		a*((a+1)+(a-1)*cos+sa),
+		-2*a*((a-1)+(a+1)*cos),
+		a*((a+1)+(a-1)*cos-sa),
+		(a+1)-(a-1)*cos+sa,
+		2*((a-1)-(a+1)*cos),
+		(a+1)-(a-1)*cos-sa,
+	)
+}
+
+func (f *biquad) peaking(sr beep.SampleRate, freq, q, db float64) {
+	a := math.Pow(10, db/40)
+	w0 := 2 * math.Pi * freq / float64(sr)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * q)
+	f.setCoefficients(1+alpha*a, -2*cos, 1-alpha*a, 1+alpha/a, -2*cos, 1-alpha/a)
+}
+
+// --- Three-Band EQ ---
+
+// threeBandEQ shapes the tone with a low shelf, a mid peak and a high shelf.
+// It isn't tied to an instrument, so it can sit anywhere a streamer can. With
+// every band at 0 dB it passes the signal through untouched.
+type threeBandEQ struct {
+	streamer   beep.Streamer
+	sampleRate beep.SampleRate
+	gains      [3]float64 // dB per band
+	bands      [3]biquad
+}
+
+func newThreeBandEQ(s beep.Streamer, sr beep.SampleRate) *threeBandEQ {
+	eq := &threeBandEQ{streamer: s, sampleRate: sr}
+	eq.setGains([3]float64{})
+	return eq
+}
+
+// setGains recomputes the filters; filter state is kept so the change doesn't
+// click.
+func (eq *threeBandEQ) setGains(gains [3]float64) {
+	eq.gains = gains
+	eq.bands[EQLow].lowShelf(eq.sampleRate, eqLowFreq, gains[EQLow])
+	eq.bands[EQMid].peaking(eq.sampleRate, eqMidFreq, eqMidQ, gains[EQMid])
+	eq.bands[EQHigh].highShelf(eq.sampleRate, eqHighFreq, gains[EQHigh])
+}
+
+func (eq *threeBandEQ) flat() bool {
+	return eq.gains == [3]float64{}
+}
+
+func (eq *threeBandEQ) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = eq.streamer.Stream(samples)
+	if eq.flat() {
+		return n, ok
+	}
+	for i := range samples[:n] {
+		for c := range samples[i] {
+			v := samples[i][c]
+			for b := range eq.bands {
+				v = eq.bands[b].process(c, v)
+			}
+			samples[i][c] = v
+		}
+	}
+	return n, ok
+}
+
+func (eq *threeBandEQ) Err() error {
+	return eq.streamer.Err()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors for the failures callers may want to tell apart, such as a
+// front-end mapping them to status codes. Match them with errors.Is.
+var (
+	ErrInstrumentNotFound = errors.New("instrumento não encontrado")
+	ErrInstrumentExists   = errors.New("instrumento já existe")
+	ErrOutOfRange         = errors.New("valor fora do intervalo")
+	ErrInvalidState       = errors.New("estado inválido para a operação")
+)
+
+// kindError carries a descriptive message while matching a sentinel.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+// errorf formats an error like fmt.Errorf that also matches kind with
+// errors.Is. The message stays exactly as formatted.
+func errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, args...)}
+}
+
+func instrumentNotFound(name string) error {
+	return errorf(ErrInstrumentNotFound, "instrumento '%s' não encontrado", name)
+}
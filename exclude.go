@@ -0,0 +1,35 @@
+package main
+
+import "log"
+
+// SetExcludeFromAll keeps the instrument out of transport commands aimed at
+// every instrument (bare play/stop, playall and the like), so a pad or a
+// metronome can keep going through them. Commands naming it still apply.
+func (i *Instrument) SetExcludeFromAll(excluded bool) {
+	i.mu.Lock()
+	i.excludeFromAll = excluded
+	i.mu.Unlock()
+	if excluded {
+		log.Printf("📌 %s fica fora dos comandos para todos.", i.name)
+	} else {
+		log.Printf("📌 %s volta a seguir os comandos para todos.", i.name)
+	}
+}
+
+// ExcludedFromAll reports whether batch transport skips the instrument.
+func (i *Instrument) ExcludedFromAll() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.excludeFromAll
+}
+
+// forEachTransport is ForEachInstrument for batch transport: instruments
+// excluded from "all" are skipped.
+func (dj *DJMixer) forEachTransport(action func(i *Instrument) error) {
+	dj.ForEachInstrument(func(i *Instrument) error {
+		if i.ExcludedFromAll() {
+			return nil
+		}
+		return action(i)
+	})
+}
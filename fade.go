@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// FadeCurve shapes how the level moves during a fade.
+type FadeCurve int
+
+const (
+	// FadeLinear moves the amplitude in a straight line.
+	FadeLinear FadeCurve = iota
+	// FadeLogarithmic moves the level evenly in decibels, which sounds even to
+	// the ear, from fadeFloorDB up to full level.
+	FadeLogarithmic
+	// FadeEqualPower follows a quarter sine, so two tracks crossfading with it
+	// keep a constant total power and the mix does not dip in the middle.
+	FadeEqualPower
+)
+
+const (
+	// DefaultFadeCurve is used by single fades unless another is chosen.
+	DefaultFadeCurve = FadeLinear
+	// DefaultCrossfadeCurve is used by crossfades unless another is chosen.
+	DefaultCrossfadeCurve = FadeEqualPower
+	fadeFloorDB           = -60.0
+	// fadeSilence stands in for a zero gain, about -120 dB, so the fader never
+	// holds -Inf (which, among other things, JSON cannot encode).
+	fadeSilence = 1e-6
+)
+
+var fadeCurveNames = []string{"linear", "log", "equal"}
+
+func (c FadeCurve) String() string {
+	return fadeCurveNames[c]
+}
+
+func parseFadeCurve(s string) (FadeCurve, error) {
+	for k, name := range fadeCurveNames {
+		if s == name {
+			return FadeCurve(k), nil
+		}
+	}
+	return 0, fmt.Errorf("curva de fade desconhecida: '%s' (use %s)", s, strings.Join(fadeCurveNames, ", "))
+}
+
+// gain returns the amplitude factor at progress x in [0, 1] of a fade in; a
+// fade out uses gain(1-x).
+func (c FadeCurve) gain(x float64) float64 {
+	x = math.Max(0, math.Min(1, x))
+	switch c {
+	case FadeLogarithmic:
+		if x == 0 {
+			return 0
+		}
+		return math.Pow(10, (1-x)*fadeFloorDB/20)
+	case FadeEqualPower:
+		return math.Sin(x * math.Pi / 2)
+	default:
+		return x
+	}
+}
+
+// fadeState tracks a fade in progress on an instrument.
+type fadeState struct {
+	cancel context.CancelFunc
+	// level is the volume the fade treats as full, restored when it ends.
+	level float64
+}
+
+// Fade brings the instrument in from silence, or takes it out to silence and
+// stops it, over duration following curve. The fader ends where it started,
+// so the next play is at the usual level. A new fade replaces one in progress.
+func (i *Instrument) Fade(in bool, duration time.Duration, curve FadeCurve) error {
+	if duration <= 0 {
+		return fmt.Errorf("duração do fade deve ser positiva")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	i.mu.Lock()
+	level := i.volume.Volume
+	if i.fade != nil {
+		i.fade.cancel()
+		level = i.fade.level
+	}
+	fade := &fadeState{cancel: cancel, level: level}
+	i.fade = fade
+	i.mu.Unlock()
+
+	shape := func(x float64) float64 {
+		if !in {
+			x = 1 - x
+		}
+		// effects.Volume works in powers of two.
+		return level + math.Log2(math.Max(curve.gain(x), fadeSilence))
+	}
+	if in {
+		i.applyVolume(shape(0))
+		if i.GetState() != StatePlaying {
+			if err := i.Play(); err != nil {
+				i.endFade(fade)
+				return err
+			}
+		}
+		log.Printf("🌅 Fade in de %s em %s (%s).", i.name, duration, curve)
+	} else {
+		log.Printf("🌇 Fade out de %s em %s (%s).", i.name, duration, curve)
+	}
+	go func() {
+		done := runRamp(ctx, duration, func(frac float64) {
+			i.applyVolume(shape(frac))
+		})
+		if !done {
+			return
+		}
+		if !in {
+			_ = i.Stop()
+		}
+		i.endFade(fade)
+	}()
+	return nil
+}
+
+// endFade puts the fader back to the fade's full level, unless a newer fade has
+// taken over.
+func (i *Instrument) endFade(f *fadeState) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.fade != f {
+		return
+	}
+	i.volume.Volume = f.level
+	i.fade = nil
+}
+
+// SetFadeCurve sets the curve fades use when the command does not name one.
+func (dj *DJMixer) SetFadeCurve(c FadeCurve) {
+	dj.mu.Lock()
+	dj.fadeCurve = c
+	dj.mu.Unlock()
+	log.Printf("📐 Curva de fade padrão: %s.", c)
+}
+
+// FadeCurve returns the default curve for fades.
+func (dj *DJMixer) FadeCurve() FadeCurve {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	return dj.fadeCurve
+}
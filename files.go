@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// supportedExtensions lists the file types the decoder can load.
+var supportedExtensions = []string{".wav"}
+
+func isSupportedExtension(ext string) bool {
+	for _, e := range supportedExtensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// scanMusicFiles returns the loadable files in dir, sorted by name.
+func scanMusicFiles(dir string) ([]string, error) {
+	var files []string
+	for _, ext := range supportedExtensions {
+		matches, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// instrumentNameFromFile derives the default instrument name from a path.
+func instrumentNameFromFile(file string) string {
+	base := filepath.Base(file)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// listMusicFiles shows which files in dir are loaded and which are available.
+func listMusicFiles(dj *DJMixer, out io.Writer, dir string) error {
+	files, err := scanMusicFiles(dir)
+	if err != nil {
+		return fmt.Errorf("falha ao ler o diretório '%s': %w", dir, err)
+	}
+	loaded := make(map[string]string)
+	for _, inst := range dj.GetAllInstrumentsSorted() {
+		if inst.path != "" {
+			loaded[filepath.Clean(inst.path)] = inst.name
+		}
+	}
+	fmt.Fprintf(out, "--- Arquivos em %s ---\n", dir)
+	for _, file := range files {
+		if name, ok := loaded[filepath.Clean(file)]; ok {
+			fmt.Fprintf(out, " ✅ %-20s (carregado como '%s')\n", filepath.Base(file), name)
+		} else {
+			fmt.Fprintf(out, " ⬜ %-20s (não carregado)\n", filepath.Base(file))
+		}
+	}
+	fmt.Fprintln(out, "--------------------")
+	return nil
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// Low-pass cutoff range in Hz, and the bounds of an auto-filter sweep.
+const (
+	MinLowPassHz = 20.0
+	MaxLowPassHz = 20000.0
+
+	MaxAutoFilterBars = 32
+
+	// autoFilterFloor is the bottom of a full-depth sweep; autoFilterTop is
+	// its top when no static cutoff is set.
+	autoFilterFloor = 150.0
+	autoFilterTop   = 16000.0
+	// autoFilterUpdate is how many samples the swept cutoff holds before the
+	// coefficients are recomputed.
+	autoFilterUpdate = 32
+)
+
+// lowPassQ is a Butterworth response: no resonant peak at the cutoff.
+const lowPassQ = math.Sqrt2 / 2
+
+// FilterShape is the LFO waveform an auto-filter sweeps with.
+type FilterShape int
+
+const (
+	// FilterTriangle sweeps up and back down linearly.
+	FilterTriangle FilterShape = iota
+	// FilterSine sweeps up and back down, easing at the ends.
+	FilterSine
+	// FilterSaw sweeps up, then drops back to the bottom.
+	FilterSaw
+)
+
+var filterShapeNames = []string{"triangle", "sine", "saw"}
+
+func (s FilterShape) String() string {
+	return filterShapeNames[s]
+}
+
+func parseFilterShape(s string) (FilterShape, error) {
+	for k, name := range filterShapeNames {
+		if s == name {
+			return FilterShape(k), nil
+		}
+	}
+	return 0, fmt.Errorf("forma do autofilter desconhecida: '%s' (use %s)", s, strings.Join(filterShapeNames, ", "))
+}
+
+// at maps a phase in [0, 1) to how far up the sweep is, in [0, 1].
+func (s FilterShape) at(phase float64) float64 {
+	switch s {
+	case FilterSine:
+		return 0.5 - 0.5*math.Cos(2*math.Pi*phase)
+	case FilterSaw:
+		return phase
+	}
+	return 1 - math.Abs(2*phase-1)
+}
+
+func (f *biquad) lowPass(sr beep.SampleRate, freq, q float64) {
+	w0 := 2 * math.Pi * freq / float64(sr)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * q)
+	f.setCoefficients((1-cos)/2, 1-cos, (1-cos)/2, 1+alpha, -2*cos, 1-alpha)
+}
+
+type autoFilterParams struct {
+	Bars  int
+	Depth float64 // fraction of the sweep range, in log frequency, 0 to 1
+	Shape FilterShape
+}
+
+// lowPass cuts the highs above a static cutoff, 0 bypassing it. An auto-filter
+// sweeps the cutoff instead, over a number of bars of the master clock.
+type lowPass struct {
+	streamer   beep.Streamer
+	sampleRate beep.SampleRate
+	cutoff     float64
+	filter     biquad
+	sweeping   bool
+	sweep      autoFilterParams
+	clock      *beatClock
+	beat       float64 // master beat at the current sample
+	step       float64 // beats per sample
+	hold       int     // samples left before the next coefficient update
+}
+
+func (lp *lowPass) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = lp.streamer.Stream(samples)
+	if !lp.sweeping && lp.cutoff <= 0 {
+		return n, ok
+	}
+	if lp.sweeping {
+		// Picking up tempo changes once per block keeps the clock's mutex off
+		// the per-sample path.
+		lp.step = lp.clock.BPM() / 60 / float64(lp.sampleRate)
+	}
+	for k := range samples[:n] {
+		if lp.sweeping {
+			if lp.hold == 0 {
+				lp.filter.lowPass(lp.sampleRate, lp.sweepCutoff(), lowPassQ)
+				lp.hold = autoFilterUpdate
+			}
+			lp.hold--
+			lp.beat += lp.step
+		}
+		for c := range samples[k] {
+			samples[k][c] = lp.filter.process(c, samples[k][c])
+		}
+	}
+	return n, ok
+}
+
+func (lp *lowPass) Err() error {
+	return lp.streamer.Err()
+}
+
+// sweepCutoff is the cutoff at the current beat. The sweep moves in log
+// frequency so it sounds even, and starts each cycle on a bar line.
+func (lp *lowPass) sweepCutoff() float64 {
+	top := lp.maxCutoff(autoFilterTop)
+	if lp.cutoff > 0 {
+		top = lp.cutoff
+	}
+	bottom := top * math.Pow(math.Min(1, autoFilterFloor/top), lp.sweep.Depth)
+	length := float64(lp.sweep.Bars * beatsPerBar)
+	phase := math.Mod(lp.beat, length) / length
+	return bottom * math.Pow(top/bottom, lp.sweep.Shape.at(phase))
+}
+
+// maxCutoff keeps a cutoff below the Nyquist frequency.
+func (lp *lowPass) maxCutoff(freq float64) float64 {
+	return math.Min(freq, 0.45*float64(lp.sampleRate))
+}
+
+// setCutoff sets the static cutoff; the caller must hold the speaker lock.
+func (lp *lowPass) setCutoff(freq float64) {
+	if lp.cutoff <= 0 && !lp.sweeping {
+		// Coming out of bypass: don't ring with stale state.
+		lp.filter = biquad{}
+	}
+	lp.cutoff = freq
+	if freq > 0 && !lp.sweeping {
+		lp.filter.lowPass(lp.sampleRate, lp.maxCutoff(freq), lowPassQ)
+	}
+}
+
+// SetLowPass sets the static low-pass cutoff in Hz; 0 turns it off.
+func (i *Instrument) SetLowPass(freq float64) error {
+	if freq != 0 && (freq < MinLowPassHz || freq > MaxLowPassHz) {
+		return errorf(ErrOutOfRange, "corte %.0f Hz está fora do intervalo [%.0f, %.0f]", freq, MinLowPassHz, MaxLowPassHz)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.lowPass.setCutoff(freq)
+	speaker.Unlock()
+	if freq == 0 {
+		log.Printf("🎛️  Filtro passa-baixa de '%s' desligado.", i.name)
+	} else {
+		log.Printf("🎛️  Filtro passa-baixa de '%s' em %.0f Hz.", i.name, freq)
+	}
+	return nil
+}
+
+// AutoFilter sweeps an instrument's low-pass cutoff with the master clock, one
+// cycle every bars bars, from the static cutoff (or the top of the range) down
+// by depth and back.
+func (dj *DJMixer) AutoFilter(name string, p autoFilterParams) error {
+	if p.Bars < 1 || p.Bars > MaxAutoFilterBars {
+		return errorf(ErrOutOfRange, "duração do autofilter %d está fora do intervalo [1, %d] compassos", p.Bars, MaxAutoFilterBars)
+	}
+	if p.Depth <= 0 || p.Depth > 1 {
+		return errorf(ErrOutOfRange, "profundidade %.2f está fora do intervalo (0, 1]", p.Depth)
+	}
+	inst, ok := dj.GetInstrument(name)
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	beat := dj.clock.Beat()
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	speaker.Lock()
+	lp := inst.lowPass
+	if lp.cutoff <= 0 && !lp.sweeping {
+		lp.filter = biquad{}
+	}
+	lp.sweeping, lp.sweep, lp.clock = true, p, dj.clock
+	lp.beat, lp.hold = beat, 0
+	speaker.Unlock()
+	log.Printf("🎛️  Autofilter em '%s': ciclo de %d compasso(s), profundidade %.2f, forma %s.", inst.name, p.Bars, p.Depth, p.Shape)
+	return nil
+}
+
+// StopAutoFilter ends the sweep, going back to the static cutoff.
+func (dj *DJMixer) StopAutoFilter(name string) error {
+	inst, ok := dj.GetInstrument(name)
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	speaker.Lock()
+	lp := inst.lowPass
+	sweeping := lp.sweeping
+	lp.sweeping = false
+	if lp.cutoff > 0 {
+		lp.filter.lowPass(lp.sampleRate, lp.maxCutoff(lp.cutoff), lowPassQ)
+	}
+	speaker.Unlock()
+	if !sweeping {
+		return fmt.Errorf("nenhum autofilter ativo em '%s'", inst.name)
+	}
+	log.Printf("🎛️  Autofilter em '%s' desligado.", inst.name)
+	return nil
+}
+
+// AutoFilterActive reports whether the instrument's cutoff is being swept.
+func (i *Instrument) AutoFilterActive() (autoFilterParams, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	speaker.Lock()
+	defer speaker.Unlock()
+	return i.lowPass.sweep, i.lowPass.sweeping
+}
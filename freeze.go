@@ -0,0 +1,195 @@
+package main
+
+import (
+	"log"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+const (
+	freezeFrameSize = 2048 // FFT size; must be a power of two
+	freezeHop       = freezeFrameSize / 4
+	freezeFade      = 20 * time.Millisecond
+)
+
+// freezeGain compensates the level lost to the Hann analysis window (mean of
+// w² is 3/8) and gained back by overlap-adding four Hann-windowed frames (sum
+// of w² is 3/2), assuming the random-phase frames are uncorrelated.
+var freezeGain = 1 / math.Sqrt(3.0/8*3.0/2)
+
+// freezer holds the sound of an instant indefinitely. While frozen, it keeps
+// resynthesizing the magnitude spectrum captured when the freeze started with
+// fresh random phases, overlap-adding Hann-windowed frames. The dry signal is
+// crossfaded in and out to avoid clicks.
+type freezer struct {
+	streamer   beep.Streamer
+	sampleRate beep.SampleRate
+	frozen     bool
+	captured   bool
+	mix        float64
+
+	window  [freezeFrameSize]float64
+	history [freezeFrameSize][2]float64 // ring of the most recent input
+	hpos    int
+	mag     [2][freezeFrameSize/2 + 1]float64
+	ola     [freezeFrameSize][2]float64
+	outPos  int
+	rng     *rand.Rand
+	bins    [2][]complex128
+	phases  [freezeFrameSize/2 + 1]complex128 // reused by every synthesized frame
+}
+
+func newFreezer(s beep.Streamer, sr beep.SampleRate) *freezer {
+	f := &freezer{
+		streamer:   s,
+		sampleRate: sr,
+		outPos:     freezeHop,
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for j := range f.window {
+		f.window[j] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(j)/freezeFrameSize)
+	}
+	for c := range f.bins {
+		f.bins[c] = make([]complex128, freezeFrameSize)
+	}
+	return f
+}
+
+func (f *freezer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = f.streamer.Stream(samples)
+	if !f.frozen && f.mix == 0 {
+		for i := range samples[:n] {
+			f.history[f.hpos] = samples[i]
+			f.hpos = (f.hpos + 1) % freezeFrameSize
+		}
+		return n, ok
+	}
+	fadeStep := 1 / float64(f.sampleRate.N(freezeFade))
+	for i := range samples[:n] {
+		if f.frozen && !f.captured {
+			f.capture()
+		}
+		if f.frozen {
+			f.mix = math.Min(1, f.mix+fadeStep)
+		} else {
+			f.captured = false
+			f.mix = math.Max(0, f.mix-fadeStep)
+			f.history[f.hpos] = samples[i]
+			f.hpos = (f.hpos + 1) % freezeFrameSize
+		}
+		if f.outPos >= freezeHop {
+			f.synthesize()
+		}
+		wet := f.ola[f.outPos]
+		f.outPos++
+		for c := range samples[i] {
+			samples[i][c] = samples[i][c]*(1-f.mix) + wet[c]*freezeGain*f.mix
+		}
+	}
+	return n, ok
+}
+
+func (f *freezer) Err() error {
+	return f.streamer.Err()
+}
+
+// capture analyses the most recent frame of input into magnitude spectra.
+func (f *freezer) capture() {
+	for c := range f.bins {
+		for j := 0; j < freezeFrameSize; j++ {
+			x := f.history[(f.hpos+j)%freezeFrameSize][c]
+			f.bins[c][j] = complex(x*f.window[j], 0)
+		}
+		fft(f.bins[c], false)
+		for k := range f.mag[c] {
+			f.mag[c][k] = cmplx.Abs(f.bins[c][k])
+		}
+	}
+	f.ola = [freezeFrameSize][2]float64{}
+	f.outPos = freezeHop
+	f.captured = true
+}
+
+// synthesize shifts the overlap-add buffer by one hop and adds a new frame
+// built from the captured magnitudes with random phases. Both channels share
+// the phases so mono material stays mono.
+func (f *freezer) synthesize() {
+	copy(f.ola[:], f.ola[freezeHop:])
+	for j := freezeFrameSize - freezeHop; j < freezeFrameSize; j++ {
+		f.ola[j] = [2]float64{}
+	}
+	half := freezeFrameSize / 2
+	phases := &f.phases
+	for k := 1; k < half; k++ {
+		phases[k] = cmplx.Rect(1, 2*math.Pi*f.rng.Float64())
+	}
+	phases[0], phases[half] = 1, 1
+	for c := range f.bins {
+		bins := f.bins[c]
+		for k := 0; k <= half; k++ {
+			bins[k] = complex(f.mag[c][k], 0) * phases[k]
+		}
+		for k := 1; k < half; k++ {
+			bins[freezeFrameSize-k] = cmplx.Conj(bins[k])
+		}
+		fft(bins, true)
+		for j := range f.ola {
+			f.ola[j][c] += real(bins[j]) * f.window[j]
+		}
+	}
+	f.outPos = 0
+}
+
+// fft computes an in-place radix-2 FFT; the inverse transform is normalized.
+func fft(a []complex128, invert bool) {
+	n := len(a)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	for length := 2; length <= n; length <<= 1 {
+		angle := 2 * math.Pi / float64(length)
+		if !invert {
+			angle = -angle
+		}
+		wl := cmplx.Rect(1, angle)
+		for i := 0; i < n; i += length {
+			w := complex(1, 0)
+			for j := 0; j < length/2; j++ {
+				u, v := a[i+j], a[i+j+length/2]*w
+				a[i+j], a[i+j+length/2] = u+v, u-v
+				w *= wl
+			}
+		}
+	}
+	if invert {
+		for i := range a {
+			a[i] /= complex(float64(n), 0)
+		}
+	}
+}
+
+// SetFreeze holds (or releases) the instrument's current sound.
+func (i *Instrument) SetFreeze(frozen bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.freezer.frozen = frozen
+	speaker.Unlock()
+	if frozen {
+		log.Printf("🧊 %s congelado.", i.name)
+	} else {
+		log.Printf("💧 %s descongelado.", i.name)
+	}
+}
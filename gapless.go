@@ -0,0 +1,191 @@
+package main
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// sourceCounter sits between the transport and the resampler and counts the
+// samples the resampler pulls, so the end of a play can be placed on the
+// output timeline despite the resampler reading ahead.
+type sourceCounter struct {
+	streamer beep.Streamer
+	pulled   int
+}
+
+func (s *sourceCounter) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = s.streamer.Stream(samples)
+	s.pulled += n
+	return n, ok
+}
+
+func (s *sourceCounter) Err() error {
+	return s.streamer.Err()
+}
+
+// handoff sits right after the resampler. It mirrors the resampler's position
+// to know on which output sample the source runs out, and can hold an armed
+// instrument silent until exactly that sample of the instrument it follows.
+type handoff struct {
+	inst     *Instrument
+	streamer beep.Streamer // the resampler
+	// pos and ratio mirror the resampler's output position and ratio; the
+	// source sample under output sample pos is pos*ratio, as in beep.
+	pos   int
+	ratio float64
+	// out counts output samples since the last sync, a timeline shared by an
+	// armed instrument and the one it follows.
+	out int
+	// endAt is the counted source sample where the current play ends, valid
+	// when endKnown.
+	endAt    int
+	endKnown bool
+	// hold keeps the instrument silent, without pulling its source, until after
+	// ends.
+	hold  bool
+	after *handoff
+}
+
+func (h *handoff) Stream(samples [][2]float64) (n int, ok bool) {
+	h.predictEnd()
+	if !h.hold {
+		n, ok = h.streamer.Stream(samples)
+		h.advance(n)
+		return n, ok
+	}
+	start := len(samples)
+	if end, known := h.after.endOutput(); known {
+		start = end - h.out
+		if start < 0 {
+			start = 0
+		}
+		if start > len(samples) {
+			start = len(samples)
+		}
+	}
+	for i := range samples[:start] {
+		samples[i] = [2]float64{}
+	}
+	h.out += start
+	if start == len(samples) {
+		return len(samples), true
+	}
+	// The followed instrument ends inside this chunk: start right on that sample.
+	h.hold, h.after = false, nil
+	n, ok = h.streamer.Stream(samples[start:])
+	h.advance(n)
+	return start + n, ok
+}
+
+func (h *handoff) Err() error {
+	return h.streamer.Err()
+}
+
+func (h *handoff) advance(n int) {
+	h.syncRatio()
+	h.pos += n
+	h.out += n
+}
+
+// syncRatio follows a ratio change the same way beep.Resampler.SetRatio does.
+func (h *handoff) syncRatio() {
+	if r := h.inst.resampler.Ratio(); r != h.ratio {
+		h.pos = int(float64(h.pos) * h.ratio / r)
+		h.ratio = r
+	}
+}
+
+// predictEnd works out where the current play will end while that is still
+// fixed: on the last pass of the loop and not paused, every remaining file
+// sample goes through the counter before the source runs out.
+func (h *handoff) predictEnd() {
+	i := h.inst
+	if i.eos.ended {
+		return
+	}
+	h.endKnown = i.loop.remaining == 1 && !i.ctrl.Paused
+	if h.endKnown {
+		h.endAt = i.counter.pulled + i.loop.pending + i.loop.passEnd() - i.streamer.Position()
+	}
+}
+
+// endOutput returns the output sample, on the out timeline, where the current
+// play ends.
+func (h *handoff) endOutput() (int, bool) {
+	h.predictEnd()
+	if !h.endKnown {
+		return 0, false
+	}
+	h.syncRatio()
+	end := float64(h.endAt)
+	p := int(math.Ceil(end / h.ratio))
+	for float64(p)*h.ratio < end {
+		p++
+	}
+	for p > 0 && float64(p-1)*h.ratio >= end {
+		p--
+	}
+	return h.out + p - h.pos, true
+}
+
+// armAfter rewinds the instrument and sets it to start on the very sample
+// where prev runs out of audio. The caller must hold i.mu.
+func (i *Instrument) armAfter(prev *Instrument) error {
+	speaker.Lock()
+	defer speaker.Unlock()
+	if err := i.rewind(); err != nil {
+		return err
+	}
+	i.resetEffectState()
+	// A fresh resampler has nothing buffered, so its first output sample is
+	// the first sample of the file.
+	i.counter.pulled = 0
+	i.resampler = beep.ResampleRatio(i.quality, i.resampler.Ratio(), i.counter)
+	i.handoff.streamer = i.resampler
+	i.handoff.pos, i.handoff.ratio = 0, i.resampler.Ratio()
+	i.handoff.hold, i.handoff.after = true, prev.handoff
+	i.handoff.out, prev.handoff.out = 0, 0
+	i.ctrl.Paused = false
+	i.volume.Silent = false
+	return nil
+}
+
+// disarm cancels a pending armAfter. The caller must hold i.mu.
+func (i *Instrument) disarm() {
+	speaker.Lock()
+	defer speaker.Unlock()
+	if !i.handoff.hold {
+		return
+	}
+	i.handoff.hold, i.handoff.after = false, nil
+	i.ctrl.Paused = true
+	i.volume.Silent = true
+}
+
+// matchSampleRate makes the resampler also convert from the file's sample rate
+// to the output rate, so files recorded at other rates play at the right speed
+// and pitch.
+func (i *Instrument) matchSampleRate(sr beep.SampleRate) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rateRatio = float64(i.format.SampleRate) / float64(sr)
+	speaker.Lock()
+	i.resampler.SetRatio(i.resampleRatio())
+	i.setOutputRate(sr)
+	speaker.Unlock()
+}
+
+// setOutputRate tells the stages after the resampler, which newInstrument set
+// up at the file's rate, that they run at the output rate sr. It is meant for
+// a fresh instrument; delay lines are cleared. The caller must hold the
+// speaker lock.
+func (i *Instrument) setOutputRate(sr beep.SampleRate) {
+	i.flanger.setSampleRate(sr)
+	i.chorus.setSampleRate(sr)
+	i.freezer.sampleRate = sr
+	i.lowPass.sampleRate = sr
+	i.ducker.sampleRate = sr
+	i.meter.window = sr.N(levelHistoryWindow)
+}
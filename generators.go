@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+const (
+	TestToneName  = "testtone"
+	TestToneLevel = 0.25 // peak amplitude, about -12 dBFS
+	MinToneFreq   = 20.0
+	MaxToneFreq   = 20000.0
+
+	NoiseName  = "noise"
+	NoiseLevel = 0.25
+
+	MetronomeName  = "metronome"
+	MetronomeLevel = 0.5
+)
+
+// metronomeClickLen is how long each click rings.
+const metronomeClickLen = 30 * time.Millisecond
+
+// signalSource adapts an endless mono signal into the seekable source an
+// instrument chain expects. It never drains; Len reports a nominal one-second
+// window so position-based commands keep working.
+type signalSource struct {
+	sampleRate beep.SampleRate
+	pos        int
+	next       func() float64
+}
+
+func (g *signalSource) Stream(samples [][2]float64) (n int, ok bool) {
+	for i := range samples {
+		v := g.next()
+		samples[i] = [2]float64{v, v}
+	}
+	g.pos += len(samples)
+	return len(samples), true
+}
+
+func (g *signalSource) Err() error    { return nil }
+func (g *signalSource) Len() int      { return int(g.sampleRate) }
+func (g *signalSource) Position() int { return g.pos % g.Len() }
+func (g *signalSource) Close() error  { return nil }
+
+func (g *signalSource) Seek(p int) error {
+	g.pos = p
+	return nil
+}
+
+// newGeneratorInstrument wraps a signal in a full instrument chain so it gets
+// its own volume, effects and transport like any loaded file.
+func newGeneratorInstrument(name string, sr beep.SampleRate, next func() float64) *Instrument {
+	format := beep.Format{SampleRate: sr, NumChannels: 1, Precision: 2}
+	inst := newInstrument(name, &signalSource{sampleRate: sr, next: next}, format)
+	inst.generator = true
+	return inst
+}
+
+// generatorSlot reports whether the generator's fixed name is in use. A file
+// loaded under that name is not the generator, and is reported as an error so
+// it is neither replaced nor removed.
+func (dj *DJMixer) generatorSlot(name string) (exists bool, err error) {
+	inst, ok := dj.GetInstrument(name)
+	if ok && !inst.generator {
+		return true, errorf(ErrInstrumentExists, "'%s' é um instrumento carregado, não o gerador", name)
+	}
+	return ok, nil
+}
+
+// sineWave returns a sine oscillator at freq Hz.
+func sineWave(sr beep.SampleRate, freq, amplitude float64) func() float64 {
+	phase := 0.0
+	step := 2 * math.Pi * freq / float64(sr)
+	return func() float64 {
+		v := amplitude * math.Sin(phase)
+		phase = math.Mod(phase+step, 2*math.Pi)
+		return v
+	}
+}
+
+// SetTestTone adds (or replaces) a sine test tone instrument playing at freq,
+// or removes it when enabled is false.
+func (dj *DJMixer) SetTestTone(freq float64, enabled bool) error {
+	exists, err := dj.generatorSlot(TestToneName)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		if !exists {
+			return fmt.Errorf("tom de teste não está ativo")
+		}
+		if err := dj.RemoveInstrument(TestToneName); err != nil {
+			return err
+		}
+		log.Printf("🔕 Tom de teste desligado.")
+		return nil
+	}
+	if freq < MinToneFreq || freq > MaxToneFreq {
+		return errorf(ErrOutOfRange, "frequência %.1f Hz está fora do intervalo [%.0f, %.0f]", freq, MinToneFreq, MaxToneFreq)
+	}
+	if exists {
+		if err := dj.RemoveInstrument(TestToneName); err != nil {
+			return err
+		}
+	}
+	inst := newGeneratorInstrument(TestToneName, dj.sampleRate, sineWave(dj.sampleRate, freq, TestToneLevel))
+	if err := dj.addInstrument(inst); err != nil {
+		return err
+	}
+	log.Printf("🔔 Tom de teste de %.1f Hz ligado (use 'volume %s <v>' para ajustar).", freq, TestToneName)
+	return inst.Play()
+}
+
+// whiteNoise returns uniformly distributed noise.
+func whiteNoise(amplitude float64) func() float64 {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return func() float64 {
+		return amplitude * (rng.Float64()*2 - 1)
+	}
+}
+
+// pinkNoise returns noise with a -3 dB/octave slope, using Paul Kellet's
+// economy filter over white noise.
+func pinkNoise(amplitude float64) func() float64 {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var b0, b1, b2 float64
+	return func() float64 {
+		white := rng.Float64()*2 - 1
+		b0 = 0.99765*b0 + white*0.0990460
+		b1 = 0.96300*b1 + white*0.2965164
+		b2 = 0.57000*b2 + white*1.0526913
+		pink := b0 + b1 + b2 + white*0.1848
+		return amplitude * pink / 4 // the filter gain peaks around 4
+	}
+}
+
+// SetNoise adds (or replaces) a white or pink noise instrument, or removes it
+// when enabled is false.
+func (dj *DJMixer) SetNoise(kind string, enabled bool) error {
+	exists, err := dj.generatorSlot(NoiseName)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		if !exists {
+			return fmt.Errorf("gerador de ruído não está ativo")
+		}
+		if err := dj.RemoveInstrument(NoiseName); err != nil {
+			return err
+		}
+		log.Printf("🔕 Gerador de ruído desligado.")
+		return nil
+	}
+	var next func() float64
+	switch kind {
+	case "white":
+		next = whiteNoise(NoiseLevel)
+	case "pink":
+		next = pinkNoise(NoiseLevel)
+	default:
+		return fmt.Errorf("tipo de ruído desconhecido: '%s' (use white ou pink)", kind)
+	}
+	if exists {
+		if err := dj.RemoveInstrument(NoiseName); err != nil {
+			return err
+		}
+	}
+	inst := newGeneratorInstrument(NoiseName, dj.sampleRate, next)
+	if err := dj.addInstrument(inst); err != nil {
+		return err
+	}
+	log.Printf("🌫️  Ruído %s ligado (use 'volume %s <v>' para ajustar).", kind, NoiseName)
+	return inst.Play()
+}
+
+// metronomeClicks returns a click on every eighth note of the clock, accented on
+// the beat, with off-beats placed by the clock's swing. It counts beats at
+// BaseBPM: the instrument's speed, which follows the master tempo like any
+// other, scales it to the master BPM.
+func metronomeClicks(sr beep.SampleRate, clock *beatClock) func() float64 {
+	clickLen := sr.N(metronomeClickLen)
+	step := BaseBPM / 60 / float64(sr)
+	beat := clock.Beat()
+	next := int(math.Ceil(beat * 2))
+	offbeat := clock.offbeat()
+	refresh := 0
+	pos := clickLen
+	var freq, amp float64
+	return func() float64 {
+		// Picking up swing changes once per block keeps the clock's mutex off
+		// the per-sample path.
+		if refresh == 0 {
+			offbeat = clock.offbeat()
+			refresh = 512
+		}
+		refresh--
+		if beat >= subdivisionBeat(next, offbeat) {
+			if next%2 == 0 {
+				freq, amp = 1500, MetronomeLevel
+			} else {
+				freq, amp = 1000, MetronomeLevel/2
+			}
+			pos = 0
+			next++
+		}
+		beat += step
+		if pos >= clickLen {
+			return 0
+		}
+		t := float64(pos) / float64(sr)
+		decay := 1 - float64(pos)/float64(clickLen)
+		pos++
+		return amp * decay * math.Sin(2*math.Pi*freq*t)
+	}
+}
+
+// SetMetronome adds a metronome instrument that clicks along with the master
+// clock, or removes it when enabled is false.
+func (dj *DJMixer) SetMetronome(enabled bool) error {
+	exists, err := dj.generatorSlot(MetronomeName)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		if !exists {
+			return fmt.Errorf("metrônomo não está ativo")
+		}
+		if err := dj.RemoveInstrument(MetronomeName); err != nil {
+			return err
+		}
+		log.Printf("🔕 Metrônomo desligado.")
+		return nil
+	}
+	if exists {
+		return errorf(ErrInvalidState, "metrônomo já está ativo")
+	}
+	inst := newGeneratorInstrument(MetronomeName, dj.sampleRate, metronomeClicks(dj.sampleRate, dj.clock))
+	inst.applySpeed(inst.clampSpeed(dj.MasterBPM() / BaseBPM))
+	if err := dj.addInstrument(inst); err != nil {
+		return err
+	}
+	log.Printf("⏱️  Metrônomo ligado a %.1f BPM (use 'volume %s <v>' para ajustar).", dj.MasterBPM(), MetronomeName)
+	return inst.Play()
+}
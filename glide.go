@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// glideStepInterval is how often a speed glide moves the resampler; finer
+// than rampStepInterval, since a glide lasts a fraction of a second.
+const glideStepInterval = 5 * time.Millisecond
+
+// speedGlideTime is how long SetSpeed takes to reach a new speed, from
+// --speed-glide; zero jumps straight there.
+func speedGlideTime() time.Duration {
+	return time.Duration(max(0, *speedGlide)) * time.Millisecond
+}
+
+// startGlide moves the resampler from ratio from to the one the speed and
+// detune ask for over d, evenly in pitch. The target is read on every step,
+// so a detune during the glide is taken along. The caller must hold i.mu.
+func (i *Instrument) startGlide(from float64, d time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancelGlide = cancel
+	go func() {
+		defer cancel()
+		ticker := time.NewTicker(glideStepInterval)
+		defer ticker.Stop()
+		start := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				frac := min(1, float64(now.Sub(start))/float64(d))
+				i.mu.Lock()
+				// A newer speed may have taken over while this waited.
+				if ctx.Err() == nil {
+					to := i.resampleRatio()
+					speaker.Lock()
+					i.resampler.SetRatio(from * math.Pow(to/from, frac))
+					speaker.Unlock()
+				}
+				i.mu.Unlock()
+				if frac == 1 {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// stopGlide abandons a glide in progress where it is; the caller must hold
+// i.mu.
+func (i *Instrument) stopGlide() {
+	if i.cancelGlide != nil {
+		i.cancelGlide()
+		i.cancelGlide = nil
+	}
+}
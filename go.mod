@@ -2,7 +2,10 @@ module go-dj
 
 go 1.22.2
 
-require github.com/faiface/beep v1.1.0
+require (
+	github.com/faiface/beep v1.1.0
+	golang.org/x/sys v0.0.0-20190626150813-e07cf5db2756
+)
 
 require (
 	github.com/hajimehoshi/oto v0.7.1 // indirect
@@ -10,5 +13,4 @@ require (
 	golang.org/x/exp v0.0.0-20190306152737-a1d7652674e8 // indirect
 	golang.org/x/image v0.0.0-20190227222117-0694c2d4d067 // indirect
 	golang.org/x/mobile v0.0.0-20190415191353-3e0bab5405d6 // indirect
-	golang.org/x/sys v0.0.0-20190626150813-e07cf5db2756 // indirect
 )
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// commandHelp holds the detailed usage shown by "help <command>": arguments,
+// ranges and examples. Commands without an entry fall back to their line in
+// the command summary.
+var commandHelp = map[string]string{
+	"play": `play [nome]
+  Toca o instrumento, ou o retoma de onde foi pausado. Sem nome, toca todos
+  (a menos que --bare-all=false). Em um grupo de choke, para os outros.
+  Exemplo: play drums`,
+	"stop": `stop [nome]
+  Silencia o instrumento; o áudio continua correndo em silêncio para manter a
+  posição sincronizada. Sem nome, para todos.
+  Exemplo: stop bass`,
+	"pause": `pause [nome]
+  Pausa na posição atual; 'play' continua dali. Sem nome, pausa todos.`,
+	"replay": `replay [nome]
+  Volta ao início (ou ao offset) e toca. Sem nome, reinicia todos.`,
+	"playfrom": `playfrom <nome> <segundos>
+  Toca a partir de uma posição do arquivo, entre 0 e a duração.
+  Exemplo: playfrom vocal 12.5`,
+	"volume": `volume <nome|all> <valor|+delta>
+  O volume vai de -2.0 a 2.0 em passos de base 2: 0 é o nível original, 1.0
+  dobra a amplitude e -1.0 a reduz à metade (cerca de 6 dB por unidade).
+  Com + o valor é relativo ao atual: +0.1 sobe, +-0.1 desce. 'all' ajusta
+  todos e instrumentos vinculados (link) mudam juntos. --max-safe-volume e
+  'limits' podem estreitar o intervalo. Desfaça com 'undo'.
+  Exemplos: volume drums -0.5 | volume all +0.2 | vol bass +-0.1`,
+	"bpm": `bpm <nome> <valor>
+  Muda o andamento do instrumento, de 60 a 240 BPM (0.5x a 2x do original de
+  120). Altera também a afinação. Vinculados mudam juntos; 'undo' desfaz.
+  Com --phase-lock, a posição musical não escorrega.
+  Exemplo: bpm drums 128`,
+	"masterbpm": `masterbpm [valor]
+  Sem argumento mostra o BPM mestre; com valor (60 a 240) o define e
+  sincroniza todos os instrumentos. Para mudar aos poucos, use 'ramp bpm'.
+  Exemplo: masterbpm 124`,
+	"ramp": `ramp bpm <alvo> <segundos>
+  Leva o BPM mestre até o alvo gradualmente. Um novo comando de tempo cancela
+  a rampa em andamento.
+  Exemplo: ramp bpm 140 30`,
+	"trim": `trim <nome> <dB>
+  Ganho de entrada antes de todos os efeitos, de -24 a 24 dB, para igualar
+  arquivos gravados em níveis diferentes.
+  Exemplo: trim vocal -3`,
+	"flanger": `flanger <nome> <hz> <profundidade> <realimentação>
+  Taxa do LFO em Hz (0 desliga), profundidade de 0 a 1 e realimentação de
+  -0.9 a 0.9 (valores fora são limitados).
+  Exemplo: flanger drums 0.25 0.8 0.5`,
+	"chorus": `chorus <nome> <hz> <profundidade> <vozes>
+  Taxa em Hz (0 desliga), profundidade de 0 a 1 e de 1 a 4 vozes.
+  Exemplo: chorus pad 0.8 0.5 3`,
+	"drive": `drive <nome> <quantidade>
+  Distorção de 0 (limpo) a 10; valores fora são limitados.
+  Exemplo: drive bass 2.5`,
+	"lowpass": `lowpass <nome> <hz|off>
+  Corta as frequências acima do corte, de 20 a 20000 Hz. 'off' desliga.
+  Exemplo: lowpass drums 800`,
+	"autofilter": `autofilter <nome> <compassos> [profundidade] [triangle|sine|saw]
+autofilter <nome> off
+  Varre o corte do passa-baixa em ciclos de 1 a 32 compassos do BPM mestre,
+  começando na linha do compasso. A profundidade (0 a 1, padrão 1) é quanto
+  o corte desce; triangle (padrão) e sine sobem e descem, saw só sobe.
+  'off' volta ao corte estático.
+  Exemplo: autofilter drums 4 0.6 sine`,
+	"width": `width <nome> <fator>
+  Largura estéreo: 0 é mono, 1 o original e até 2 mais aberto.
+  Exemplo: width pad 1.5`,
+	"fade": `fade <nome> in|out <segundos> [linear|log|equal]
+  Sobe do silêncio até o volume atual, ou desce até silenciar e parar. Sem
+  curva, usa a de 'fadecurve'.
+  Exemplo: fade pad out 8 log`,
+	"loop": `loop <nome> <n>|inf
+  Toca o arquivo n vezes e para, ou repete indefinidamente com inf.
+  Exemplo: loop fill 2`,
+	"roll": `roll <nome> <divisãoInicial> <divisãoFinal> <compassos>
+roll <nome> off
+  Repete um trecho do que está tocando, de 1/d1 a 1/d2 de nota (1 a 64), por
+  1 a 16 compassos do BPM mestre; depois o instrumento volta onde estaria.
+  Exemplo: roll drums 4 32 2`,
+	"cue": `cue set|jump|clear <nome> <n>
+  Hot cues de 1 a 8: set marca o ponto que está tocando, jump toca a partir
+  dele e clear o apaga. Cues gravados no WAV são carregados com o arquivo.
+  Exemplo: cue jump vocal 2`,
+	"xfader": `xfader [assign <a> <b> | <posição> | off]
+  'assign' põe dois instrumentos nos lados A e B; a posição vai de 0 (só A)
+  a 1 (só B). 'off' libera os instrumentos; sem argumentos, mostra o estado.
+  Exemplos: xfader assign drums bass | xfader 0.5`,
+	"scene": `scene save <nome> | scene recall <nome> [segundos] | scene list
+  Guarda volumes, BPMs, estados e efeitos de todos os instrumentos; recall
+  os restaura, com transição gradual opcional.
+  Exemplo: scene recall drop 4`,
+	"preset": `preset save <preset> <nome> | preset apply <preset> <nome> | preset list
+  Guarda a cadeia de efeitos de um instrumento num arquivo de presets e a
+  aplica em outro (desfazível com 'undo').
+  Exemplo: preset apply sujo bass`,
+	"session": `session save|load [arquivo]
+  Grava ou restaura a mixagem inteira em JSON (padrão: go-dj-session.json).`,
+	"link": `link <nomes...>
+  Vincula instrumentos: volume e BPM de um valem para todos. Sem argumentos,
+  lista os grupos; 'unlink <nome>' desfaz.
+  Exemplo: link drums perc`,
+	"duck": `duck <fonte> <alvos...> <quantidade> [ms]
+duck <fonte> off
+  Abaixa os alvos enquanto a fonte toca (sidechain), de 0 a 4 unidades de
+  volume, voltando em [ms] milissegundos (padrão 250) quando ela silencia.
+  Exemplo: duck kick bass pad 1.5 150`,
+	"mixdown": `mixdown <arquivo> <segundos>
+  Renderiza a mixagem atual em um arquivo WAV, sem tocar nos alto-falantes.
+  Exemplo: mixdown ensaio.wav 60`,
+	"undo": `undo
+  Desfaz a última mudança de volume ou BPM (ou a aplicação de um preset).`,
+	"help": `help [comando]
+  Sem argumento, lista todos os comandos; com um, mostra os detalhes dele.
+  Exemplo: help volume`,
+}
+
+// helpAliases maps short command names to the entry that documents them.
+var helpAliases = map[string]string{
+	"vol": "volume", "start": "play", "h": "help", "u": "undo", "pl": "playlist", "ls": "list", "wave": "waveform",
+}
+
+// printCommandHelp writes the detailed help of one command, following command
+// aliases from the config file.
+func printCommandHelp(dj *DJMixer, out io.Writer, name string) error {
+	dj.mu.RLock()
+	if target, ok := dj.aliases[name]; ok {
+		name = target
+	}
+	dj.mu.RUnlock()
+	if target, ok := helpAliases[name]; ok {
+		name = target
+	}
+	if text, ok := commandHelp[name]; ok {
+		fmt.Fprintln(out, text)
+		return nil
+	}
+	if lines := summaryLines(name); len(lines) > 0 {
+		for _, line := range lines {
+			fmt.Fprintln(out, line)
+		}
+		return nil
+	}
+	return fmt.Errorf("comando desconhecido: '%s' (use 'help' para ver a lista)", name)
+}
+
+// summaryLines returns the lines of the command summary that describe name.
+func summaryLines(name string) []string {
+	var buf bytes.Buffer
+	printHelp(&buf)
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		synopsis, _, found := strings.Cut(strings.TrimSpace(line), " - ")
+		if !found {
+			continue
+		}
+		// "playall | pauseall" and "enable|disable <nome>" name several
+		// commands.
+		for _, alt := range strings.Split(synopsis, " | ") {
+			fields := strings.Fields(alt)
+			if len(fields) == 0 {
+				continue
+			}
+			for _, cmd := range strings.Split(fields[0], "|") {
+				if cmd == name {
+					lines = append(lines, strings.TrimSpace(line))
+				}
+			}
+		}
+	}
+	return lines
+}
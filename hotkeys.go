@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// HotkeyVolumeStep is how much + and - move the selected instrument's volume.
+const HotkeyVolumeStep = 0.1
+
+// Key codes and escape sequences read in hotkey mode.
+const (
+	keyEsc    = "\x1b"
+	keyCtrlC  = "\x03"
+	keyUp     = "\x1b[A"
+	keyDown   = "\x1b[B"
+	keyRight  = "\x1b[C"
+	keyLeft   = "\x1b[D"
+	keySpace  = " "
+	keyPlus   = "+"
+	keyEquals = "=" // + without shift on most layouts
+	keyMinus  = "-"
+)
+
+// runHotkeys reads single keys from the terminal until Esc: arrows select an
+// instrument, space toggles play/pause and +/- change its volume. The status
+// line under the log shows the selection.
+func runHotkeys(dj *DJMixer, in io.Reader, out io.Writer) error {
+	restore, err := enableRawMode()
+	if err != nil {
+		return fmt.Errorf("falha ao ativar o modo de teclas: %w", err)
+	}
+	defer restore()
+	fmt.Fprintln(out, "⌨️  Modo de teclas: ←/→ seleciona, espaço toca/pausa, +/- volume, Esc sai.")
+
+	selected := 0
+	buf := make([]byte, 16)
+	for {
+		instruments := dj.GetAllInstrumentsSorted()
+		if len(instruments) == 0 {
+			fmt.Fprintln(out, "\r\nNenhum instrumento carregado.")
+			return nil
+		}
+		if selected >= len(instruments) {
+			selected = len(instruments) - 1
+		}
+		fmt.Fprint(out, "\r\x1b[K"+hotkeyStatusLine(instruments, selected))
+
+		n, err := in.Read(buf)
+		if err != nil {
+			fmt.Fprint(out, "\r\n")
+			return err
+		}
+		inst := instruments[selected]
+		var actionErr error
+		switch string(buf[:n]) {
+		case keyEsc, keyCtrlC, "q":
+			fmt.Fprint(out, "\r\n")
+			return nil
+		case keyLeft, keyUp:
+			selected = (selected + len(instruments) - 1) % len(instruments)
+			continue
+		case keyRight, keyDown:
+			selected = (selected + 1) % len(instruments)
+			continue
+		case keySpace:
+			fmt.Fprint(out, "\r\n")
+			if inst.GetState() == StatePlaying {
+				actionErr = inst.Pause()
+			} else {
+				actionErr = inst.Play()
+			}
+		case keyPlus, keyEquals:
+			fmt.Fprint(out, "\r\n")
+			actionErr = inst.SetVolume(inst.Volume() + HotkeyVolumeStep)
+		case keyMinus:
+			fmt.Fprint(out, "\r\n")
+			actionErr = inst.SetVolume(inst.Volume() - HotkeyVolumeStep)
+		default:
+			continue
+		}
+		if actionErr != nil {
+			fmt.Fprintf(out, "❌ Erro: %v\r\n", actionErr)
+		}
+	}
+}
+
+// hotkeyStatusLine lists the instruments with the selected one highlighted.
+func hotkeyStatusLine(instruments []*Instrument, selected int) string {
+	items := make([]string, len(instruments))
+	for k, inst := range instruments {
+		icon := strings.TrimSpace(icons().state(inst.GetState()))
+		item := fmt.Sprintf("%s %s %+.1f", icon, inst.name, inst.Volume())
+		if k == selected {
+			item = "\x1b[7m[" + item + "]\x1b[0m" // reverse video
+		}
+		items[k] = item
+	}
+	return strings.Join(items, "  ")
+}
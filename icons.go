@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// iconTheme holds the icons used by list and the transport messages. Each icon
+// includes the spacing it needs to line up, since some emoji render one cell
+// wide and others two.
+type iconTheme struct {
+	Playing   string
+	Paused    string
+	Stopped   string
+	Replay    string
+	PlayFrom  string
+	MasterBPM string
+	MasterVol string
+	Alias     string
+}
+
+var iconThemes = map[string]iconTheme{
+	"emoji": {
+		Playing:   "▶️ ",
+		Paused:    "⏸️ ",
+		Stopped:   "🔇",
+		Replay:    "🔄",
+		PlayFrom:  "⏩",
+		MasterBPM: "🥁",
+		MasterVol: "🔊",
+		Alias:     "♊",
+	},
+	// plain sticks to ASCII for terminals and logs without emoji fonts.
+	"plain": {
+		Playing:   "> ",
+		Paused:    "||",
+		Stopped:   "x ",
+		Replay:    ">>",
+		PlayFrom:  ">|",
+		MasterBPM: "*",
+		MasterVol: "*",
+		Alias:     "= ",
+	},
+}
+
+// currentIcons is the theme in use, chosen by --icons or the config file. It
+// can change on a config reload while other goroutines log.
+var currentIcons atomic.Pointer[iconTheme]
+
+// icons returns the theme in use.
+func icons() iconTheme {
+	if t := currentIcons.Load(); t != nil {
+		return *t
+	}
+	return iconThemes["emoji"]
+}
+
+// setIconTheme switches to the named theme.
+func setIconTheme(name string) error {
+	theme, ok := iconThemes[name]
+	if !ok {
+		names := make([]string, 0, len(iconThemes))
+		for n := range iconThemes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("tema de ícones desconhecido: '%s' (use %s)", name, strings.Join(names, " ou "))
+	}
+	currentIcons.Store(&theme)
+	return nil
+}
+
+// state returns the icon for an instrument state.
+func (t iconTheme) state(s InstrumentState) string {
+	switch s {
+	case StatePlaying:
+		return t.Playing
+	case StatePaused:
+		return t.Paused
+	default:
+		return t.Stopped
+	}
+}
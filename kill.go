@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// eqBandArgs are the band names the kill command takes, by EQ band index.
+var eqBandArgs = [3]string{"low", "mid", "high"}
+
+// Crossover points of the isolator, in Hz: the edges of the EQ's bands.
+const (
+	isoLowFreq  = eqLowFreq
+	isoHighFreq = eqHighFreq
+)
+
+// isoFade is how long a band takes to go out or come back: fast enough to
+// fall on the beat, slow enough not to click.
+const isoFade = 20 * time.Millisecond
+
+func parseEQBand(s string) (int, error) {
+	for b, name := range eqBandArgs {
+		if s == name {
+			return b, nil
+		}
+	}
+	return 0, fmt.Errorf("banda inválida: %s (use low, mid ou high)", s)
+}
+
+func (f *biquad) highPass(sr beep.SampleRate, freq, q float64) {
+	w0 := 2 * math.Pi * freq / float64(sr)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * q)
+	f.setCoefficients((1+cos)/2, -(1 + cos), (1+cos)/2, 1+alpha, -2*cos, 1-alpha)
+}
+
+func (f *biquad) allPass(sr beep.SampleRate, freq, q float64) {
+	w0 := 2 * math.Pi * freq / float64(sr)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * q)
+	f.setCoefficients(1-alpha, -2*cos, 1+alpha, 1+alpha, -2*cos, 1-alpha)
+}
+
+// clear forgets the filter's history.
+func (f *biquad) clear() {
+	f.x1, f.x2, f.y1, f.y2 = [2]float64{}, [2]float64{}, [2]float64{}, [2]float64{}
+}
+
+// lr4 is a fourth-order Linkwitz-Riley filter: two Butterworth sections in
+// series. Its low-pass and high-pass at one frequency add up to a second-order
+// all-pass, so the bands they split sum back to a flat response.
+type lr4 [2]biquad
+
+func (f *lr4) process(c int, x float64) float64 {
+	return f[1].process(c, f[0].process(c, x))
+}
+
+// isolator splits the master into low, mid and high bands with Linkwitz-Riley
+// crossovers and scales each band, like the isolator on a DJ mixer: a killed
+// band is gone while the others stay at full level, which shelving or peaking
+// EQ filters can't do. With no band killed it passes the signal through
+// untouched. The split signal is phase shifted around the crossovers, so it is
+// crossfaded in from the dry one, and back out once every band has returned.
+type isolator struct {
+	streamer       beep.Streamer
+	lowLP, lowHP   lr4
+	highLP, highHP lr4
+	lowAP          biquad // gives the low band the high crossover's phase shift
+	killed         [3]bool
+	gains          [3]float64 // linear level per band, moving towards the kills
+	wet            float64    // share of the split signal in the output
+	step           float64    // how far gains and wet move per sample
+}
+
+func newIsolator(s beep.Streamer, sr beep.SampleRate) *isolator {
+	iso := &isolator{streamer: s, gains: [3]float64{1, 1, 1}, step: 1 / float64(sr.N(isoFade))}
+	q := 1 / math.Sqrt2
+	for k := range iso.lowLP {
+		iso.lowLP[k].lowPass(sr, isoLowFreq, q)
+		iso.lowHP[k].highPass(sr, isoLowFreq, q)
+		iso.highLP[k].lowPass(sr, isoHighFreq, q)
+		iso.highHP[k].highPass(sr, isoHighFreq, q)
+	}
+	iso.lowAP.allPass(sr, isoHighFreq, q)
+	return iso
+}
+
+// idle reports whether the isolator has nothing to do.
+func (iso *isolator) idle() bool {
+	return iso.wet == 0 && iso.killed == [3]bool{}
+}
+
+// approach moves v one step towards target.
+func (iso *isolator) approach(v, target float64) float64 {
+	if math.Abs(target-v) <= iso.step {
+		return target
+	}
+	return v + math.Copysign(iso.step, target-v)
+}
+
+func (iso *isolator) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = iso.streamer.Stream(samples)
+	if iso.idle() {
+		return n, ok
+	}
+	for i := range samples[:n] {
+		settled := true
+		for b := range iso.gains {
+			target := 1.0
+			if iso.killed[b] {
+				target = 0
+			}
+			iso.gains[b] = iso.approach(iso.gains[b], target)
+			settled = settled && iso.gains[b] == 1
+		}
+		wetTarget := 1.0
+		if settled {
+			wetTarget = 0
+		}
+		iso.wet = iso.approach(iso.wet, wetTarget)
+		for c := range samples[i] {
+			x := samples[i][c]
+			low := iso.lowAP.process(c, iso.lowLP.process(c, x))
+			rest := iso.lowHP.process(c, x)
+			mid := iso.highLP.process(c, rest)
+			high := iso.highHP.process(c, rest)
+			split := iso.gains[EQLow]*low + iso.gains[EQMid]*mid + iso.gains[EQHigh]*high
+			samples[i][c] = (1-iso.wet)*x + iso.wet*split
+		}
+	}
+	if iso.idle() {
+		// The next kill starts the filters afresh; the crossfade covers their
+		// settling.
+		iso.reset()
+	}
+	return n, ok
+}
+
+func (iso *isolator) Err() error {
+	return iso.streamer.Err()
+}
+
+// reset clears the filter state, keeping the coefficients.
+func (iso *isolator) reset() {
+	for _, f := range []*lr4{&iso.lowLP, &iso.lowHP, &iso.highLP, &iso.highHP} {
+		f[0].clear()
+		f[1].clear()
+	}
+	iso.lowAP.clear()
+}
+
+// killedNames lists the killed bands in Portuguese, low to high.
+func (iso *isolator) killedNames() []string {
+	var names []string
+	for b, killed := range iso.killed {
+		if killed {
+			names = append(names, eqBandNames[b])
+		}
+	}
+	return names
+}
+
+// SetKill cuts one band of the master completely, like the isolator on a DJ
+// mixer, or brings it back. The master EQ's gains still apply to the bands
+// that play. The band fades over isoFade, so the toggle is immediate without
+// clicking.
+func (dj *DJMixer) SetKill(band int, on bool) error {
+	speaker.Lock()
+	iso := dj.master.iso
+	if on == iso.killed[band] {
+		speaker.Unlock()
+		if on {
+			return errorf(ErrInvalidState, "%s já estão cortados", eqBandNames[band])
+		}
+		return errorf(ErrInvalidState, "%s não estão cortados", eqBandNames[band])
+	}
+	iso.killed[band] = on
+	speaker.Unlock()
+	if on {
+		log.Printf("✂️  Kill: %s cortados na saída mestre.", eqBandNames[band])
+	} else {
+		log.Printf("🎛️  Kill desligado: %s de volta.", eqBandNames[band])
+	}
+	return nil
+}
+
+// KilledBands reports which master bands are killed.
+func (dj *DJMixer) KilledBands() [3]bool {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return dj.master.iso.killed
+}
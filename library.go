@@ -0,0 +1,117 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/faiface/beep"
+)
+
+// loadReadyCount is how many files must be through before the command prompt
+// opens while the rest of the library keeps loading.
+const loadReadyCount = 4
+
+// loadProgress counts the files of the startup load, for the list header.
+type loadProgress struct {
+	done, total atomic.Int32
+}
+
+// pending reports how far the load is while it runs.
+func (p *loadProgress) pending() (done, total int, loading bool) {
+	done, total = int(p.done.Load()), int(p.total.Load())
+	return done, total, done < total
+}
+
+// loadResult is the outcome of loading one file: the error, or the file's
+// own sample rate.
+type loadResult struct {
+	file string
+	rate beep.SampleRate
+	err  error
+}
+
+// LoadLibrary adds an instrument for every file, decoding them on a pool of
+// workers. ready is closed once the first few files are through, so the
+// prompt can open early, and done once every file is. Files that failed and
+// files whose sample rate differs from the output's are reported together at
+// the end.
+func (dj *DJMixer) LoadLibrary(files []string, workers int) (ready, done <-chan struct{}) {
+	readyCh, doneCh := make(chan struct{}), make(chan struct{})
+	if len(files) == 0 {
+		close(readyCh)
+		close(doneCh)
+		return readyCh, doneCh
+	}
+	workers = max(1, min(workers, len(files)))
+	jobs := make(chan string)
+	results := make(chan loadResult)
+	dj.loading.total.Store(int32(len(files)))
+	go func() {
+		for _, file := range files {
+			jobs <- file
+		}
+		close(jobs)
+	}()
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				name := instrumentNameFromFile(file)
+				r := loadResult{file: file, err: dj.AddInstrument(name, file)}
+				if inst, ok := dj.GetInstrument(name); ok && r.err == nil {
+					r.rate = inst.format.SampleRate
+				}
+				results <- r
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	go func() {
+		var failed, mismatched []loadResult
+		count, total := 0, len(files)
+		readyAt := min(loadReadyCount, total)
+		step := max(1, total/10)
+		for r := range results {
+			count++
+			dj.loading.done.Store(int32(count))
+			if r.err != nil {
+				failed = append(failed, r)
+			} else if r.rate != 0 && r.rate != dj.sampleRate {
+				mismatched = append(mismatched, r)
+			}
+			if count == readyAt {
+				close(readyCh)
+			}
+			if count%step == 0 || count == total {
+				log.Printf("📦 Carregados %d/%d.", count, total)
+			}
+		}
+		byFile := func(rs []loadResult) {
+			sort.Slice(rs, func(a, b int) bool { return rs[a].file < rs[b].file })
+		}
+		if len(mismatched) > 0 {
+			byFile(mismatched)
+			log.Printf("⚠️  %d arquivo(s) em taxa de amostragem diferente da saída (%d Hz); são reamostrados, o que custa CPU e pode mudar um pouco o som:", len(mismatched), dj.sampleRate)
+			for _, m := range mismatched {
+				log.Printf("   %s: %d Hz", m.file, m.rate)
+			}
+		}
+		if len(failed) > 0 {
+			byFile(failed)
+			log.Printf("⚠️  %d arquivo(s) não carregado(s):", len(failed))
+			for _, f := range failed {
+				log.Printf("   %s: %v", f.file, f.err)
+			}
+		}
+		close(doneCh)
+	}()
+	return readyCh, doneCh
+}
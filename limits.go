@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// instrumentLimits bounds the volume and speed an instrument accepts. They
+// default to the global ranges and can only be tightened.
+type instrumentLimits struct {
+	MinVolume float64 `json:"minVolume"`
+	MaxVolume float64 `json:"maxVolume"`
+	MinSpeed  float64 `json:"minSpeed"`
+	MaxSpeed  float64 `json:"maxSpeed"`
+}
+
+// limitArgs names the limits command's arguments, in order, for its errors.
+var limitArgs = [4]string{"volume mínimo", "volume máximo", "velocidade mínima", "velocidade máxima"}
+
+func defaultLimits() instrumentLimits {
+	return instrumentLimits{
+		MinVolume: MinVolume,
+		MaxVolume: MaxVolume,
+		MinSpeed:  MinSpeedRatio,
+		MaxSpeed:  MaxSpeedRatio,
+	}
+}
+
+func (l instrumentLimits) validate() error {
+	if !within(l.MinVolume, MinVolume, l.MaxVolume) || !within(l.MaxVolume, l.MinVolume, MaxVolume) {
+		return fmt.Errorf("limites de volume [%.2f, %.2f] inválidos: devem estar dentro de [%.2f, %.2f]", l.MinVolume, l.MaxVolume, MinVolume, MaxVolume)
+	}
+	if !within(l.MinSpeed, MinSpeedRatio, l.MaxSpeed) || !within(l.MaxSpeed, l.MinSpeed, MaxSpeedRatio) {
+		return fmt.Errorf("limites de velocidade [%.2f, %.2f] inválidos: devem estar dentro de [%.2f, %.2f]", l.MinSpeed, l.MaxSpeed, MinSpeedRatio, MaxSpeedRatio)
+	}
+	return nil
+}
+
+// SetLimits tightens the volume and speed ranges for this instrument. Current
+// values outside the new ranges are left alone until the next change.
+func (i *Instrument) SetLimits(minVol, maxVol, minSpeed, maxSpeed float64) error {
+	l := instrumentLimits{MinVolume: minVol, MaxVolume: maxVol, MinSpeed: minSpeed, MaxSpeed: maxSpeed}
+	if err := l.validate(); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	i.limits = l
+	i.mu.Unlock()
+	log.Printf("🛡️  Limites de '%s': volume [%.2f, %.2f], velocidade [%.2fx, %.2fx].", i.name, minVol, maxVol, minSpeed, maxSpeed)
+	return nil
+}
+
+// ResetLimits restores the global ranges.
+func (i *Instrument) ResetLimits() {
+	i.mu.Lock()
+	i.limits = defaultLimits()
+	i.mu.Unlock()
+	log.Printf("🛡️  Limites de '%s' restaurados para os globais.", i.name)
+}
+
+func (i *Instrument) Limits() instrumentLimits {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.limits
+}
+
+// clampSpeed fits ratio into the instrument's speed limits, for changes that
+// apply to every instrument at once, such as the master tempo.
+func (i *Instrument) clampSpeed(ratio float64) float64 {
+	l := i.Limits()
+	return math.Max(l.MinSpeed, math.Min(l.MaxSpeed, ratio))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Link joins instruments so that volume and BPM changes made to one apply to
+// all of them, as for stereo pairs or layered sounds. Instruments already in a
+// group bring their group along.
+func (dj *DJMixer) Link(names []string) error {
+	if len(names) < 2 {
+		return fmt.Errorf("informe ao menos dois instrumentos para vincular")
+	}
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	for k, name := range names {
+		name = dj.resolveName(name)
+		if _, ok := dj.instruments[name]; !ok {
+			return instrumentNotFound(name)
+		}
+		names[k] = name
+	}
+	if dj.links == nil {
+		dj.links = make(map[string]int)
+	}
+	group, ok := dj.links[names[0]]
+	if !ok {
+		dj.lastLink++
+		group = dj.lastLink
+	}
+	for _, name := range names {
+		if old, linked := dj.links[name]; linked && old != group {
+			for _, member := range dj.linkMembers(old) {
+				dj.links[member] = group
+			}
+		}
+		dj.links[name] = group
+	}
+	log.Printf("🔗 Vinculados: %s.", strings.Join(dj.linkMembers(group), ", "))
+	return nil
+}
+
+// Unlink takes an instrument out of its link group; a group left with a single
+// member is dissolved.
+func (dj *DJMixer) Unlink(name string) error {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	name = dj.resolveName(name)
+	group, ok := dj.links[name]
+	if !ok {
+		return errorf(ErrInvalidState, "instrumento '%s' não está vinculado", name)
+	}
+	delete(dj.links, name)
+	if rest := dj.linkMembers(group); len(rest) == 1 {
+		delete(dj.links, rest[0])
+	}
+	log.Printf("🔗 '%s' desvinculado.", name)
+	return nil
+}
+
+// linkMembers returns the sorted members of group; the caller must hold dj.mu.
+func (dj *DJMixer) linkMembers(group int) []string {
+	var members []string
+	for name, g := range dj.links {
+		if g == group {
+			members = append(members, name)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// linked returns inst followed by the other members of its link group.
+func (dj *DJMixer) linked(inst *Instrument) []*Instrument {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	members := []*Instrument{inst}
+	if group, ok := dj.links[inst.name]; ok {
+		for _, name := range dj.linkMembers(group) {
+			if other := dj.instruments[name]; other != nil && other != inst {
+				members = append(members, other)
+			}
+		}
+	}
+	return members
+}
+
+func printLinks(dj *DJMixer, out io.Writer) {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	groups := make(map[int]bool)
+	for _, g := range dj.links {
+		groups[g] = true
+	}
+	ids := make([]int, 0, len(groups))
+	for g := range groups {
+		ids = append(ids, g)
+	}
+	sort.Ints(ids)
+	fmt.Fprintln(out, "--- Vínculos ---")
+	for _, g := range ids {
+		fmt.Fprintf(out, " 🔗 %s\n", strings.Join(dj.linkMembers(g), ", "))
+	}
+	fmt.Fprintln(out, "----------------")
+}
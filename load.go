@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// DownloadTimeout bounds the whole download of a remote file.
+	DownloadTimeout = 60 * time.Second
+	// MaxDownloadSize guards against pulling in something that is not a loop.
+	MaxDownloadSize = 512 << 20
+)
+
+// contentTypeExtensions maps the audio content types servers send to the file
+// extension the decoder dispatch understands.
+var contentTypeExtensions = map[string]string{
+	"audio/wav":      ".wav",
+	"audio/x-wav":    ".wav",
+	"audio/wave":     ".wav",
+	"audio/vnd.wave": ".wav",
+}
+
+// Load adds an instrument from a local path or an http(s) URL.
+func (dj *DJMixer) Load(name, source string) error {
+	if !isURL(source) {
+		return dj.AddInstrument(name, source)
+	}
+	tmp, err := download(source)
+	if err != nil {
+		return err
+	}
+	if err := dj.AddInstrument(name, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	dj.mu.RLock()
+	inst, loaded := dj.instruments[name]
+	dj.mu.RUnlock()
+	if !loaded || inst.path != tmp {
+		// -dedupe turned it into an alias; the download is not needed.
+		os.Remove(tmp)
+		return nil
+	}
+	inst.mu.Lock()
+	inst.tempFile = true
+	inst.mu.Unlock()
+	return nil
+}
+
+func isURL(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// download fetches rawURL into a temporary file and returns its path. The file
+// gets an extension from the URL or, failing that, from the content type, so the
+// decoder can tell what it is.
+func download(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("URL inválida %s: %w", rawURL, err)
+	}
+	client := &http.Client{Timeout: DownloadTimeout}
+	log.Printf("🌐 Baixando %s...", rawURL)
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return "", fmt.Errorf("falha ao baixar %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("falha ao baixar %s: %s", rawURL, resp.Status)
+	}
+
+	ext := strings.ToLower(path.Ext(u.Path))
+	if !isSupportedExtension(ext) {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		ext = contentTypeExtensions[mediaType]
+		if ext == "" {
+			return "", fmt.Errorf("formato de %s não suportado (tipo %q); use %s", rawURL, resp.Header.Get("Content-Type"), strings.Join(supportedExtensions, ", "))
+		}
+	}
+
+	f, err := os.CreateTemp("", "go-dj-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("falha ao criar arquivo temporário: %w", err)
+	}
+	n, err := io.Copy(f, io.LimitReader(resp.Body, MaxDownloadSize+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > MaxDownloadSize {
+		err = fmt.Errorf("arquivo maior que %d MB", MaxDownloadSize>>20)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("falha ao baixar %s: %w", rawURL, err)
+	}
+	log.Printf("🌐 %d KB baixados de %s.", n>>10, rawURL)
+	return f.Name(), nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// loopStreamer plays its source count times in a row (forever when count is
+// negative) and drains once the plays are exhausted. With humanize on, each
+// restart lands up to jitter samples early or late: the pass before an early
+// restart is cut short and a late one is preceded by silence, so the offsets
+// never accumulate.
+type loopStreamer struct {
+	streamer  beep.StreamSeeker
+	count     int
+	remaining int
+	// start and end bound the part of the source that loops, end exclusive;
+	// an end of 0 is the end of the source.
+	start, end int
+
+	jitter  int // maximum restart offset in samples; 0 is strict timing
+	offset  int // offset of the current pass
+	next    int // offset chosen for the next pass
+	pending int // silence still to play before the current pass
+}
+
+func (l *loopStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	if l.remaining == 0 || l.streamer.Err() != nil {
+		return 0, false
+	}
+	for len(samples) > 0 {
+		if l.pending > 0 {
+			k := l.pending
+			if k > len(samples) {
+				k = len(samples)
+			}
+			for i := range samples[:k] {
+				samples[i] = [2]float64{}
+			}
+			samples = samples[k:]
+			n += k
+			l.pending -= k
+			continue
+		}
+		chunk := samples
+		end := l.passEnd()
+		if left := end - l.streamer.Position(); left < len(chunk) {
+			chunk = chunk[:max(left, 0)]
+		}
+		sn, sok := 0, true
+		if len(chunk) > 0 {
+			sn, sok = l.streamer.Stream(chunk)
+		}
+		if sn == 0 && len(chunk) > 0 && l.streamer.Position() == l.start {
+			// A source that yields nothing from its start, such as a file
+			// truncated on disk, would otherwise restart here forever.
+			return n, n > 0
+		}
+		samples = samples[sn:]
+		n += sn
+		if sok && sn > 0 && l.streamer.Position() < end {
+			continue
+		}
+		if l.remaining > 0 {
+			l.remaining--
+		}
+		if l.remaining == 0 || l.streamer.Seek(l.start) != nil {
+			return n, n > 0
+		}
+		l.nextPass()
+	}
+	return n, true
+}
+
+func (l *loopStreamer) Err() error {
+	return l.streamer.Err()
+}
+
+// region returns the looping part of the source, end exclusive.
+func (l *loopStreamer) region() (start, end int) {
+	end = l.streamer.Len()
+	if l.end > 0 && l.end < end {
+		end = l.end
+	}
+	return l.start, end
+}
+
+// passEnd is where the current pass stops: the end of the loop region, or
+// earlier when the next restart comes early.
+func (l *loopStreamer) passEnd() int {
+	_, end := l.region()
+	if l.jitter > 0 && l.remaining != 1 && l.next < l.offset {
+		end -= l.offset - l.next
+	}
+	return end
+}
+
+// nextPass moves on to the offset chosen for the pass just starting and picks
+// the one after it.
+func (l *loopStreamer) nextPass() {
+	if l.jitter == 0 {
+		return
+	}
+	if l.next > l.offset {
+		l.pending = l.next - l.offset
+	}
+	l.offset = l.next
+	l.next = rand.Intn(2*l.jitter+1) - l.jitter
+}
+
+// rewind seeks the source back to the start of the loop and restores the play
+// count; the caller must hold the speaker lock.
+func (l *loopStreamer) rewind() error {
+	if err := l.streamer.Seek(l.start); err != nil {
+		return err
+	}
+	l.remaining = l.count
+	l.resetJitter()
+	return nil
+}
+
+// resetJitter starts the restart offsets over from strict timing.
+func (l *loopStreamer) resetJitter() {
+	l.offset, l.pending = 0, 0
+	l.next = 0
+	if l.jitter > 0 {
+		l.next = rand.Intn(2*l.jitter+1) - l.jitter
+	}
+}
+
+// endNotifier turns the end of its source into silence and signals it once
+// through onEnd. It never drains itself, so the instrument stays in the mixer
+// and can be played again after a rewind.
+type endNotifier struct {
+	streamer beep.Streamer
+	ended    bool
+	onEnd    func()
+}
+
+func (e *endNotifier) Stream(samples [][2]float64) (n int, ok bool) {
+	if !e.ended {
+		n, ok = e.streamer.Stream(samples)
+		if !ok || n < len(samples) {
+			e.ended = true
+			if e.onEnd != nil {
+				// onEnd runs on its own goroutine: we hold the speaker lock here
+				// and the instrument takes its own lock before the speaker's.
+				go e.onEnd()
+			}
+		}
+	}
+	for i := range samples[n:] {
+		samples[n+i] = [2]float64{}
+	}
+	return len(samples), true
+}
+
+func (e *endNotifier) Err() error {
+	return e.streamer.Err()
+}
+
+// rewind restarts the loop at the instrument's offset and re-arms the end
+// notification; the caller must hold i.mu and the speaker lock.
+func (i *Instrument) rewind() error {
+	if err := i.loop.rewind(); err != nil {
+		return err
+	}
+	if i.offset > 0 {
+		if err := i.streamer.Seek(i.loop.start + i.offsetSample()); err != nil {
+			return err
+		}
+	}
+	i.eos.ended = false
+	return nil
+}
+
+// seek moves the playhead to sample n, re-arming the loop count and clearing
+// effect tails; the caller must hold the speaker lock.
+func (i *Instrument) seek(n int) error {
+	if err := i.streamer.Seek(n); err != nil {
+		return err
+	}
+	i.loop.remaining = i.loop.count
+	i.loop.resetJitter()
+	i.eos.ended = false
+	i.resetEffectState()
+	return nil
+}
+
+// SetLoopCount sets how many times the file plays before the instrument stops;
+// a negative count loops forever.
+func (i *Instrument) SetLoopCount(count int) error {
+	if count == 0 {
+		return fmt.Errorf("número de repetições deve ser positivo (ou negativo para infinito)")
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.loop.count = count
+	i.loop.remaining = count
+	speaker.Unlock()
+	if count < 0 {
+		log.Printf("🔁 %s vai repetir indefinidamente.", i.name)
+	} else {
+		log.Printf("🔂 %s vai tocar %d vez(es) e parar.", i.name, count)
+	}
+	return nil
+}
+
+// SetRepeat toggles between infinite looping and playing the file once.
+func (i *Instrument) SetRepeat(repeat bool) {
+	if repeat {
+		_ = i.SetLoopCount(-1)
+	} else {
+		_ = i.SetLoopCount(1)
+	}
+}
+
+// SetRetrigger makes Play restart from the top every time, like a one-shot
+// sampler, instead of resuming where the instrument was paused.
+func (i *Instrument) SetRetrigger(on bool) {
+	i.mu.Lock()
+	i.retrigger = on
+	i.mu.Unlock()
+	if on {
+		log.Printf("🎯 %s reinicia do começo a cada play.", i.name)
+	} else {
+		log.Printf("🎯 %s retoma de onde parou ao tocar.", i.name)
+	}
+}
+
+// Retrigger reports whether Play restarts from the top.
+func (i *Instrument) Retrigger() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.retrigger
+}
+
+// LoopCount returns the configured number of plays; negative means forever.
+func (i *Instrument) LoopCount() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.loop.count
+}
+
+// onStreamEnd stops the instrument once its plays are exhausted and then runs
+// its end hook, if any.
+func (i *Instrument) onStreamEnd() {
+	i.mu.Lock()
+	// The fader stays open: the end is seen as the resampler reads ahead, and
+	// what it already buffered still has to play out. Only silence follows.
+	speaker.Lock()
+	ended := i.eos.ended
+	speaker.Unlock()
+	if !ended {
+		i.mu.Unlock()
+		return
+	}
+	if i.state != StateStopped {
+		i.state = StateStopped
+		log.Printf("⏹️  %s chegou ao fim e parou.", i.name)
+	}
+	hook := i.endHook
+	i.mu.Unlock()
+	if hook != nil {
+		hook()
+	}
+}
+
+// MaxHumanize is the largest restart offset humanize accepts.
+const MaxHumanize = 100 * time.Millisecond
+
+// SetHumanize makes each loop restart land at a random offset within ±jitter
+// of the beat, so repeated hits sound less mechanical; zero restores strict
+// timing.
+func (i *Instrument) SetHumanize(jitter time.Duration) error {
+	if jitter < 0 || jitter > MaxHumanize {
+		return errorf(ErrOutOfRange, "humanize %s está fora do intervalo [0, %s]", jitter, MaxHumanize)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.loop.jitter = i.format.SampleRate.N(jitter)
+	i.loop.resetJitter()
+	speaker.Unlock()
+	if jitter == 0 {
+		log.Printf("🤖 %s com tempo estrito.", i.name)
+	} else {
+		log.Printf("🧑 %s com humanize de ±%s nos reinícios.", i.name, jitter)
+	}
+	return nil
+}
+
+// Humanize returns the maximum restart offset.
+func (i *Instrument) Humanize() time.Duration {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	speaker.Lock()
+	defer speaker.Unlock()
+	return i.format.SampleRate.D(i.loop.jitter)
+}
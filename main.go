@@ -3,13 +3,17 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"os"
 	"os/signal"
-	"path/filepath"
+	"runtime"
+	"runtime/debug"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -32,6 +36,33 @@ const (
 	MaxSpeedRatio = 2.0
 )
 
+// --- Command-Line Flags ---
+var (
+	tcpAddr     = flag.String("tcp", "", "endereço para o servidor de comandos TCP (ex: ':7000')")
+	configPath  = flag.String("config", DefaultConfigFile, "arquivo de configuração JSON")
+	presetsPath = flag.String("presets", DefaultPresetsFile, "arquivo JSON onde os presets de efeitos são guardados")
+	musicDir    = flag.String("dir", AudioDir, "diretório com os arquivos de áudio")
+	bareAll     = flag.Bool("bare-all", true, "play/pause/stop/replay sem instrumento afetam todos (use false para exigir playall etc.)")
+	exitOnEOF   = flag.Bool("exit-on-eof", false, "encerra ao fim da entrada padrão (padrão quando a entrada não é um terminal)")
+	dedupe      = flag.Bool("dedupe", false, "detecta arquivos com conteúdo idêntico e os carrega uma só vez, como apelido")
+	panicLogDir = flag.String("panic-log", ".", "diretório onde pânicos recuperados são registrados com a pilha de chamadas")
+	outputRate  = flag.Int("rate", 0, "taxa de amostragem da saída em Hz (0 = a do primeiro arquivo); os arquivos são reamostrados para ela")
+	quality     = flag.Int("resample-quality", DefaultResampleQuality, "qualidade do reamostrador (1-6); valores altos reduzem aliasing mas custam mais CPU")
+	sessionLog  = flag.String("transcript", "", "grava cada comando, com horário e pausas, num arquivo que pode ser reexecutado como script")
+	phaseLock   = flag.Bool("phase-lock", false, "mudanças de BPM mantêm a posição musical exata do loop, sem escorregar a cada ajuste")
+	autosave    = flag.Bool("autosave", false, "salva a sessão em "+DefaultSessionFile+" ao encerrar")
+	resume      = flag.Bool("resume", false, "retoma a sessão salva em "+DefaultSessionFile+" ao iniciar")
+	maxSafeVol  = flag.Float64("max-safe-volume", MaxVolume, "volume máximo de qualquer instrumento, com aviso ao se aproximar (ex: 1.0 = 2x); protege ouvidos e caixas")
+	iconStyle   = flag.String("icons", "emoji", "ícones da lista e das mensagens: emoji ou plain (ASCII, para terminais sem emoji)")
+	trimQuiet   = flag.Bool("trim-silence", false, "ao carregar, tira do loop o silêncio no início e no fim de cada arquivo")
+	silenceDBFS = flag.Float64("silence-threshold", DefaultSilenceDB, "nível em dBFS abaixo do qual o início e o fim do arquivo contam como silêncio")
+	stopModeArg = flag.String("stop-mode", "mute", "o que stop faz: mute (continua em fase, em silêncio) ou pause (economiza CPU, mas perde a fase)")
+	speedGlide  = flag.Int("speed-glide", 0, "tempo em ms que uma mudança de BPM leva para chegar ao novo valor, sem saltos (0 = imediato)")
+	followTempo = flag.Bool("tempo-follow", false, "o primeiro instrumento tocado define o BPM mestre")
+	loadWorkers = flag.Int("load-workers", runtime.NumCPU(), "quantos arquivos são carregados ao mesmo tempo na inicialização")
+	throttleDur = flag.Duration("throttle", 0, "agrupa mudanças rápidas de volume/BPM/efeitos no mesmo parâmetro, aplicando a última a cada intervalo (ex: 20ms)")
+)
+
 // --- Type Definitions ---
 type InstrumentState int
 
@@ -48,64 +79,236 @@ func (s InstrumentState) String() string {
 type Instrument struct {
 	name       string
 	streamer   beep.StreamSeekCloser
+	loop       *loopStreamer
+	eos        *endNotifier
+	trim       *effects.Gain
+	trimDB     float64
 	ctrl       *beep.Ctrl
+	poly       *voicePool
+	counter    *sourceCounter
+	handoff    *handoff
+	roll       *beatRoll
 	volume     *effects.Volume
 	resampler  *beep.Resampler
+	flanger    *flanger
+	chorus     *chorus
+	freezer    *freezer
+	drive      *drive
+	lowPass    *lowPass
+	swap       *channelSwap
+	invert     *phaseInvert
+	width      *stereoWidth
+	channels   *channelGain
+	ducker     *ducker
+	solo       *soloCut
+	xfade      *effects.Gain // crossfader level, unity unless on a deck
+	meter      *levelMeter
+	format     beep.Format
 	state      InstrumentState
 	speedRatio float64
+	rateRatio  float64 // file sample rate over output sample rate
+	detune     float64 // fine pitch offset in cents, on top of speedRatio
+	quality    int     // resampler quality
+	limits     instrumentLimits
+	endHook    func() // called without i.mu held once a finite play ends
 	mu         sync.RWMutex
-	file       *os.File
+	path       string       // source file; empty for generators
+	hash       string       // SHA-256 of the file, when -dedupe is on
+	tempFile   bool         // path is a download to delete on Close
+	generator  bool         // test tone, noise or metronome, see generatorSlot
+	disabled   bool         // disconnected from the mixer
+	waveform   []waveBucket // cached file envelope, see Waveform
+	soloSafe   bool
+	retrigger  bool        // play always restarts from the top, like replay
+	offset     float64     // beats every rewind skips, see SetOffset
+	cues       map[int]int // hot-cue slot → file sample
+	silenceDB  float64     // threshold the loop was trimmed at, 0 if untrimmed
+	fade       *fadeState  // fade in progress, if any
+	// abOther is the A/B side not being heard, nil until "ab store"; the
+	// stored reference is playing when abReference is set.
+	abOther     *effectSettings
+	abReference bool
+	// cancelGlide stops the speed glide in progress, if any; see startGlide.
+	cancelGlide context.CancelFunc
+	// excludeFromAll keeps batch transport away, see SetExcludeFromAll.
+	excludeFromAll bool
 }
 
 type DJMixer struct {
 	instruments map[string]*Instrument
 	mixer       beep.Mixer
-	mu          sync.RWMutex
+	master      *masterBus
+	sampleRate  beep.SampleRate
+	undo        undoStack
+	scenes      map[string]scene
+	presets     map[string]effectSettings
+	// links maps an instrument name to its link group.
+	links       map[string]int
+	transcript  *transcript // nil unless --transcript is set
+	lastLink    int
+	presetsPath string
+	aliases     map[string]string
+	prompt      string  // template from the config file, see renderPrompt
+	config      *Config // last applied, to report what a reload changes
+	// cancelTransition stops the scene transition currently in progress, if any.
+	cancelTransition context.CancelFunc
+	clock            *beatClock
+	// cancelRamp stops the master tempo ramp currently in progress, if any.
+	cancelRamp context.CancelFunc
+	// loading tracks the startup load of the music folder.
+	loading loadProgress
+	// tempoFollow makes the next play set the master BPM, see SetTempoFollow.
+	tempoFollow bool
+	// armed holds the instruments the next "go" starts together.
+	armed    map[string]bool
+	playlist playlist
+	// instrumentAliases maps the name of a duplicate file to the instrument
+	// already loaded with the same content.
+	instrumentAliases map[string]string
+	// soloed holds the soloed instruments; when it is not empty everything
+	// else that is not solo-safe is cut.
+	soloed map[string]bool
+	// bindings maps the number slots of "bind" to instrument names.
+	bindings map[int]string
+	// patterns holds the one-bar step patterns running, by instrument.
+	patterns map[string]*stepPattern
+	// chokeGroups maps an instrument name to its exclusive group.
+	chokeGroups map[string]string
+	fadeCurve   FadeCurve
+	xfader      crossfader
+	throttle    *commandThrottle // nil unless --throttle is set
+	// sleepTimer, sleepAt and cancelSleep track the pending sleep timer.
+	sleepTimer  *time.Timer
+	sleepAt     time.Time
+	cancelSleep context.CancelFunc
+	// cancelBeatVis stops the beat indicator, if it is on.
+	cancelBeatVis context.CancelFunc
+	mu            sync.RWMutex
 }
 
 // --- Instrument Methods ---
 
 func NewInstrument(name, filename string) (*Instrument, error) {
+	streamer, format, err := decodeFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	inst := newInstrument(name, streamer, format)
+	inst.path = filename
+	inst.loadFileCues()
+	return inst, nil
+}
+
+// decodeFile opens and decodes an audio file.
+func decodeFile(filename string) (beep.StreamSeekCloser, beep.Format, error) {
 	f, err := os.Open(filename)
 	if err != nil {
-		return nil, fmt.Errorf("falha ao abrir arquivo %s: %w", filename, err)
+		return nil, beep.Format{}, fmt.Errorf("falha ao abrir arquivo %s: %w", filename, err)
 	}
-	streamer, _, err := wav.Decode(f)
+	streamer, format, err := wav.Decode(f)
 	if err != nil {
 		f.Close()
-		return nil, fmt.Errorf("falha ao decodificar arquivo WAV %s: %w", filename, err)
+		return nil, beep.Format{}, fmt.Errorf("falha ao decodificar arquivo WAV %s: %w", filename, err)
 	}
-	loopedStreamer := beep.Loop(-1, streamer)
-	ctrl := &beep.Ctrl{Streamer: loopedStreamer, Paused: true}
-	resampler := beep.ResampleRatio(4, 1.0, ctrl)
+	return streamer, format, nil
+}
+
+// newInstrument builds the processing chain around any seekable source, be it
+// a decoded file or a generator.
+func newInstrument(name string, streamer beep.StreamSeekCloser, format beep.Format) *Instrument {
+	loop := &loopStreamer{streamer: streamer, count: -1, remaining: -1}
+	eos := &endNotifier{streamer: loop}
+	trim := &effects.Gain{Streamer: eos} // Input trim comes first, before any processing
+	ctrl := &beep.Ctrl{Streamer: trim, Paused: true}
+	poly := &voicePool{streamer: ctrl, trim: trim}
+	counter := &sourceCounter{streamer: poly}
+	resampler := beep.ResampleRatio(*quality, 1.0, counter)
+	handoff := &handoff{streamer: resampler, ratio: 1.0}
+	roll := &beatRoll{streamer: handoff}
+	flanger := newFlanger(roll, format.SampleRate)
+	chorus := newChorus(flanger, format.SampleRate)
+	freezer := newFreezer(chorus, format.SampleRate)
+	drive := &drive{streamer: freezer}
+	lowPass := &lowPass{streamer: drive, sampleRate: format.SampleRate}
+	swap := &channelSwap{streamer: lowPass}
+	invert := &phaseInvert{streamer: swap}
+	width := &stereoWidth{streamer: invert, factor: 1}
+	channels := &channelGain{streamer: width, gains: [2]float64{1, 1}}
 	volume := &effects.Volume{
-		Streamer: resampler, // Volume now wraps the resampler directly
+		Streamer: channels, // Effects run before the fader so their level can be compensated
 		Base:     2,
 		Volume:   DefaultVolume,
 		Silent:   true, // Start silently until played
 	}
-	return &Instrument{
+	ducker := &ducker{streamer: volume, sampleRate: format.SampleRate, release: DefaultDuckRelease}
+	solo := &soloCut{streamer: ducker}
+	xfade := &effects.Gain{Streamer: solo}
+	meter := newLevelMeter(xfade, format.SampleRate)
+	inst := &Instrument{
 		name:       name,
 		streamer:   streamer,
+		loop:       loop,
+		eos:        eos,
+		trim:       trim,
 		ctrl:       ctrl,
+		poly:       poly,
+		counter:    counter,
+		handoff:    handoff,
+		roll:       roll,
 		volume:     volume,
 		resampler:  resampler,
+		flanger:    flanger,
+		chorus:     chorus,
+		freezer:    freezer,
+		drive:      drive,
+		lowPass:    lowPass,
+		swap:       swap,
+		invert:     invert,
+		width:      width,
+		channels:   channels,
+		ducker:     ducker,
+		solo:       solo,
+		xfade:      xfade,
+		meter:      meter,
+		format:     format,
 		state:      StateStopped,
 		speedRatio: 1.0,
-		file:       f,
-	}, nil
+		rateRatio:  1.0,
+		quality:    *quality,
+		limits:     defaultLimits(),
+	}
+	eos.onEnd = inst.onStreamEnd
+	handoff.inst = inst
+	return inst
 }
 
-func (i *Instrument) SetSpeed(ratio float64) error {
-	if ratio < MinSpeedRatio || ratio > MaxSpeedRatio {
-		return fmt.Errorf("proporção de velocidade %.2f está fora do intervalo [%.2f, %.2f]", ratio, MinSpeedRatio, MaxSpeedRatio)
+// retime moves the resampler to the ratio the speed and detune now ask for,
+// the way a change from the user should: keeping the loop's phase with
+// --phase-lock, over --speed-glide when set, and at once otherwise. The caller
+// must hold i.mu and the speaker lock.
+func (i *Instrument) retime() {
+	i.stopGlide()
+	if *phaseLock {
+		// Phase lock restarts the source at the exact spot, so it can't glide.
+		i.retimeKeepingPhase(i.resampleRatio())
+	} else if glide := speedGlideTime(); glide > 0 {
+		i.startGlide(i.resampler.Ratio(), glide)
+	} else {
+		i.resampler.SetRatio(i.resampleRatio())
 	}
+}
+
+func (i *Instrument) SetSpeed(ratio float64) error {
 	i.mu.Lock()
+	if !within(ratio, i.limits.MinSpeed, i.limits.MaxSpeed) {
+		i.mu.Unlock()
+		return errorf(ErrOutOfRange, "proporção de velocidade %.2f está fora do intervalo [%.2f, %.2f]", ratio, i.limits.MinSpeed, i.limits.MaxSpeed)
+	}
 	i.speedRatio = ratio
-	i.mu.Unlock()
 	speaker.Lock()
-	i.resampler.SetRatio(ratio)
+	i.retime()
 	speaker.Unlock()
+	i.mu.Unlock()
 	currentBPM := BaseBPM * ratio
 	log.Printf("🎹 Tempo para '%s' definido para %.1f BPM (%.2fx).", i.name, currentBPM, ratio)
 	return nil
@@ -114,26 +317,73 @@ func (i *Instrument) SetSpeed(ratio float64) error {
 func (i *Instrument) Play() error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
-	if i.state == StatePlaying {
-		return fmt.Errorf("instrumento '%s' já está tocando", i.name)
+	if i.polyphonic() {
+		i.trigger()
+		return nil
+	}
+	if i.state == StatePlaying && !i.retrigger {
+		return errorf(ErrInvalidState, "instrumento '%s' já está tocando", i.name)
+	}
+	speaker.Lock()
+	if i.eos.ended || i.retrigger {
+		// A finite loop that reached its end starts over, and a retriggered
+		// instrument always does.
+		if err := i.rewind(); err != nil {
+			speaker.Unlock()
+			return fmt.Errorf("falha ao reiniciar '%s': %w", i.name, err)
+		}
+		i.resetEffectState()
 	}
+	speaker.Unlock()
 	i.volume.Silent = false // Unmute the track
 	i.ctrl.Paused = false
 	i.state = StatePlaying
-	log.Printf("▶️  %s começou a tocar.", i.name)
+	log.Printf("%s %s começou a tocar.", icons().Playing, i.name)
 	return nil
 }
 
 func (i *Instrument) Replay() error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
-	if err := i.streamer.Seek(0); err != nil {
+	if i.polyphonic() {
+		i.trigger()
+		return nil
+	}
+	speaker.Lock()
+	err := i.rewind()
+	if err == nil {
+		i.resetEffectState()
+	}
+	speaker.Unlock()
+	if err != nil {
 		return fmt.Errorf("falha ao reiniciar '%s': %w", i.name, err)
 	}
 	i.volume.Silent = false // Unmute the track
 	i.ctrl.Paused = false
 	i.state = StatePlaying
-	log.Printf("🔄 %s tocando novamente desde o início.", i.name)
+	log.Printf("%s %s tocando novamente desde o início.", icons().Replay, i.name)
+	return nil
+}
+
+// PlayFrom seeks to pos and starts playing from there.
+func (i *Instrument) PlayFrom(pos time.Duration) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	length := i.streamer.Len()
+	n := i.format.SampleRate.N(pos)
+	if n < 0 || n >= length {
+		return errorf(ErrOutOfRange, "posição %s está fora do intervalo [0s, %s)", pos, i.format.SampleRate.D(length).Round(time.Millisecond))
+	}
+	speaker.Lock()
+	err := i.seek(n)
+	speaker.Unlock()
+	if err != nil {
+		return fmt.Errorf("falha ao posicionar '%s': %w", i.name, err)
+	}
+	i.volume.Silent = false
+	i.ctrl.Paused = false
+	i.state = StatePlaying
+	log.Printf("%s %s tocando a partir de %s.", icons().PlayFrom, i.name, pos)
 	return nil
 }
 
@@ -141,11 +391,14 @@ func (i *Instrument) Pause() error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 	if i.state != StatePlaying {
-		return fmt.Errorf("instrumento '%s' não está tocando (estado atual: %s)", i.name, i.state)
+		return errorf(ErrInvalidState, "instrumento '%s' não está tocando (estado atual: %s)", i.name, i.state)
+	}
+	if i.polyphonic() {
+		i.silenceVoices()
 	}
 	i.ctrl.Paused = true
 	i.state = StatePaused
-	log.Printf("⏸️  %s pausado.", i.name)
+	log.Printf("%s %s pausado.", icons().Paused, i.name)
 	return nil
 }
 
@@ -155,24 +408,86 @@ func (i *Instrument) Stop() error {
 	if i.state == StateStopped {
 		return nil
 	}
-	// Stop now mutes the track but lets it play silently in the background.
+	// Stop mutes the track but, in the default mute mode, lets it play
+	// silently in the background so it keeps its phase. Voices are one-shots,
+	// so they are cut rather than left running.
+	if i.polyphonic() {
+		i.silenceVoices()
+	}
+	speaker.Lock()
+	i.roll.cancel()
+	speaker.Unlock()
 	i.volume.Silent = true
+	if stopMode() == StopPause {
+		i.ctrl.Paused = true
+	}
 	i.state = StateStopped
-	log.Printf("🔇 %s silenciado (parado).", i.name)
+	log.Printf("%s %s silenciado (parado).", icons().Stopped, i.name)
+	return nil
+}
+
+// Reset returns the instrument to its freshly loaded state: default volume,
+// original speed, every effect bypassed, playhead at the start and stopped.
+func (i *Instrument) Reset() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.loop.count = -1
+	i.offset = 0
+	err := i.rewind()
+	i.stopGlide()
+	i.speedRatio, i.detune = 1.0, 0
+	i.resampler.SetRatio(i.resampleRatio())
+	i.setEffects(defaultEffectSettings())
+	i.resetEffectState()
+	i.ducker.source = nil
+	i.ducker.sourceName = ""
+	i.volume.Volume = DefaultVolume
+	i.volume.Silent = true
+	speaker.Unlock()
+	i.state = StateStopped
+	if err != nil {
+		return fmt.Errorf("falha ao reiniciar '%s': %w", i.name, err)
+	}
+	log.Printf("♻️  %s restaurado para os valores padrão.", i.name)
 	return nil
 }
 
 func (i *Instrument) SetVolume(vol float64) error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
-	if vol < MinVolume || vol > MaxVolume {
-		return fmt.Errorf("volume %.2f está fora do intervalo permitido [%.2f, %.2f]", vol, MinVolume, MaxVolume)
+	if !within(vol, i.limits.MinVolume, i.limits.MaxVolume) {
+		return errorf(ErrOutOfRange, "volume %.2f está fora do intervalo permitido [%.2f, %.2f]", vol, i.limits.MinVolume, i.limits.MaxVolume)
 	}
+	vol = safeVolume(i.name, vol)
 	i.volume.Volume = vol
 	log.Printf("🔊 Volume de %s definido para %.2f.", i.name, vol)
 	return nil
 }
 
+// AdjustVolume moves the volume by delta, stopping at the instrument's limits
+// instead of failing, so a relative change applies to every instrument alike.
+func (i *Instrument) AdjustVolume(delta float64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	vol := math.Max(i.limits.MinVolume, math.Min(i.limits.MaxVolume, i.volume.Volume+delta))
+	vol = safeVolume(i.name, vol)
+	i.volume.Volume = vol
+	log.Printf("🔊 Volume de %s definido para %.2f.", i.name, vol)
+}
+
+func (i *Instrument) Volume() float64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.volume.Volume
+}
+
+func (i *Instrument) SpeedRatio() float64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.speedRatio
+}
+
 func (i *Instrument) GetState() InstrumentState {
 	i.mu.RLock()
 	defer i.mu.RUnlock()
@@ -182,37 +497,145 @@ func (i *Instrument) GetState() InstrumentState {
 func (i *Instrument) Close() error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
-	return i.file.Close()
+	i.stopGlide()
+	if i.tempFile {
+		defer os.Remove(i.path)
+	}
+	return i.streamer.Close()
 }
 
 // --- DJMixer Methods ---
 
-func NewDJMixer() *DJMixer {
-	return &DJMixer{
+func NewDJMixer(sampleRate beep.SampleRate) *DJMixer {
+	dj := &DJMixer{
 		instruments: make(map[string]*Instrument),
+		sampleRate:  sampleRate,
+		clock:       newBeatClock(BaseBPM),
+		playlist:    playlist{current: -1},
+		fadeCurve:   DefaultFadeCurve,
 	}
+	dj.master = newMasterBus(&dj.mixer, sampleRate)
+	return dj
 }
 
 func (dj *DJMixer) AddInstrument(name, filepath string) error {
-	dj.mu.Lock()
-	defer dj.mu.Unlock()
-	if _, exists := dj.instruments[name]; exists {
-		return fmt.Errorf("instrumento '%s' já existe", name)
+	dj.mu.RLock()
+	exists := dj.nameTaken(name)
+	dj.mu.RUnlock()
+	if exists {
+		return errorf(ErrInstrumentExists, "instrumento '%s' já existe", name)
+	}
+	var hash string
+	if *dedupe {
+		h, err := fileHash(filepath)
+		if err != nil {
+			return err
+		}
+		dj.mu.Lock()
+		dup := dj.aliasDuplicate(name, h)
+		dj.mu.Unlock()
+		if dup {
+			return nil
+		}
+		hash = h
 	}
+	// The file is opened and prepared without dj.mu held, so several can load
+	// at once; the name is checked again when the instrument goes in.
 	inst, err := NewInstrument(name, filepath)
 	if err != nil {
 		return err
 	}
+	inst.hash = hash
+	if inst.format.SampleRate != dj.sampleRate {
+		log.Printf("🎚️  '%s' está em %d Hz; reamostrando para %d Hz.", name, inst.format.SampleRate, dj.sampleRate)
+		inst.matchSampleRate(dj.sampleRate)
+	}
+	if *trimQuiet {
+		if err := inst.TrimSilence(*silenceDBFS); err != nil {
+			log.Printf("⚠️  Silêncio de '%s' não removido: %v", name, err)
+		}
+	}
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	if dj.nameTaken(name) {
+		inst.Close()
+		return errorf(ErrInstrumentExists, "instrumento '%s' já existe", name)
+	}
+	if hash != "" && dj.aliasDuplicate(name, hash) {
+		inst.Close()
+		return nil
+	}
 	dj.instruments[name] = inst
-	dj.mixer.Add(inst.volume)
-	log.Printf("✅ Instrumento '%s' carregado com sucesso.", name)
+	speaker.Lock()
+	inst.solo.cut = len(dj.soloed) > 0
+	dj.mixer.Add(inst.meter)
+	speaker.Unlock()
+	log.Printf("✅ Instrumento '%s' carregado com sucesso (%s).", name, inst.formatSummary())
+	return nil
+}
+
+// addInstrument registers an already built instrument, such as a generator.
+func (dj *DJMixer) addInstrument(inst *Instrument) error {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	if dj.nameTaken(inst.name) {
+		return errorf(ErrInstrumentExists, "instrumento '%s' já existe", inst.name)
+	}
+	dj.instruments[inst.name] = inst
+	speaker.Lock()
+	inst.solo.cut = len(dj.soloed) > 0
+	dj.mixer.Add(inst.meter)
+	speaker.Unlock()
 	return nil
 }
 
+// RemoveInstrument detaches an instrument from the mix and releases it.
+func (dj *DJMixer) RemoveInstrument(name string) error {
+	dj.mu.Lock()
+	if target, alias := dj.instrumentAliases[name]; alias {
+		delete(dj.instrumentAliases, name)
+		dj.mu.Unlock()
+		log.Printf("🗑️  Apelido '%s' (de '%s') removido.", name, target)
+		return nil
+	}
+	inst, ok := dj.instruments[name]
+	if ok {
+		delete(dj.instruments, name)
+		for alias, target := range dj.instrumentAliases {
+			if target == name {
+				delete(dj.instrumentAliases, alias)
+			}
+		}
+	}
+	for slot, bound := range dj.bindings {
+		if bound == name {
+			delete(dj.bindings, slot)
+		}
+	}
+	if p, running := dj.patterns[name]; running {
+		p.cancel()
+		delete(dj.patterns, name)
+	}
+	wasSoloed := dj.soloed[name]
+	delete(dj.soloed, name)
+	dj.mu.Unlock()
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	if wasSoloed {
+		dj.applySolo()
+	}
+	speaker.Lock()
+	// The mixer drops streamers once they drain.
+	inst.meter.detached = true
+	speaker.Unlock()
+	return inst.Close()
+}
+
 func (dj *DJMixer) GetInstrument(name string) (*Instrument, bool) {
 	dj.mu.RLock()
 	defer dj.mu.RUnlock()
-	inst, ok := dj.instruments[name]
+	inst, ok := dj.instruments[dj.resolveName(name)]
 	return inst, ok
 }
 
@@ -236,54 +659,198 @@ func (dj *DJMixer) Close() {
 	dj.mu.Lock()
 	defer dj.mu.Unlock()
 	log.Println("Desligando todos os instrumentos...")
+	if dj.cancelBeatVis != nil {
+		// The program may exit before the indicator goroutine cleans up.
+		dj.cancelBeatVis()
+		setWindowTitle(os.Stdout, windowTitle)
+	}
 	for _, inst := range dj.instruments {
 		_ = inst.Stop()
 		_ = inst.Close()
 	}
 	dj.instruments = make(map[string]*Instrument)
+	_ = dj.transcript.Close()
+	dj.transcript = nil
+}
+
+// ForEachInstrument applies action to every instrument, logging (rather than
+// aborting on) individual failures.
+func (dj *DJMixer) ForEachInstrument(action func(i *Instrument) error) {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	for _, inst := range dj.instruments {
+		if e := action(inst); e != nil {
+			log.Printf("⚠️  Ignorando erro na operação em lote para '%s': %v", inst.name, e)
+		}
+	}
 }
 
 // --- Main Application & Command Loop ---
 
+// transportActions maps the transport commands to their instrument methods.
+var transportActions = map[string]func(i *Instrument) error{
+	"play":   func(i *Instrument) error { return i.Play() },
+	"start":  func(i *Instrument) error { return i.Play() },
+	"pause":  func(i *Instrument) error { return i.Pause() },
+	"stop":   func(i *Instrument) error { return i.Stop() },
+	"replay": func(i *Instrument) error { return i.Replay() },
+}
+
 func main() {
+	flag.Parse()
 	log.SetFlags(0)
+	if err := validateResampleQuality(*quality); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := setIconTheme(*iconStyle); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := validateSafeVolume(*maxSafeVol); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if m, err := parseStopMode(*stopModeArg); err != nil {
+		log.Fatalf("❌ %v", err)
+	} else {
+		setStopMode(m)
+	}
 	log.Println("🎧 Mesa de DJ Inicializando...")
 
 	shutdownChan := make(chan os.Signal, 1)
 	signal.Notify(shutdownChan, os.Interrupt, syscall.SIGTERM)
 
-	audioFiles, err := filepath.Glob(filepath.Join(AudioDir, "*.wav"))
+	audioFiles, err := scanMusicFiles(*musicDir)
 	if err != nil || len(audioFiles) == 0 {
-		log.Fatalf("❌ Nenhum arquivo WAV encontrado em '%s'. Erro: %v", AudioDir, err)
+		log.Fatalf("❌ Nenhum arquivo WAV encontrado em '%s'. Erro: %v", *musicDir, err)
 	}
 
-	sampleRate, err := getSampleRateFromFile(audioFiles[0])
-	if err != nil {
+	var sampleRate beep.SampleRate
+	if *outputRate != 0 {
+		if err := validateSampleRate(*outputRate); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		sampleRate = beep.SampleRate(*outputRate)
+		log.Printf("🎵 Saída forçada em %d Hz; arquivos em outras taxas serão reamostrados.", sampleRate)
+	} else if sampleRate, err = getSampleRateFromFile(audioFiles[0]); err != nil {
 		log.Fatalf("❌ Não foi possível determinar a taxa de amostragem: %v", err)
 	}
 
-	if err := speaker.Init(sampleRate, sampleRate.N(time.Second/10)); err != nil {
-		log.Fatalf("❌ Falha ao inicializar o alto-falante: %v", err)
+	if err := initSpeaker(sampleRate); err != nil {
+		log.Fatalf("❌ Falha ao inicializar o alto-falante em %d Hz: %v", sampleRate, err)
 	}
 	defer speaker.Close()
 
-	mixer := NewDJMixer()
+	mixer := NewDJMixer(sampleRate)
 	defer mixer.Close()
 
-	for _, file := range audioFiles {
-		instrumentName := strings.TrimSuffix(filepath.Base(file), ".wav")
-		if err := mixer.AddInstrument(instrumentName, file); err != nil {
-			log.Printf("⚠️  Não foi possível carregar '%s': %v", instrumentName, err)
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	mixer.ApplyConfig(cfg)
+	mixer.SetThrottle(*throttleDur)
+	if err := mixer.LoadPresets(*presetsPath); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if *sessionLog != "" {
+		if err := mixer.StartTranscript(*sessionLog); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
+	// SIGHUP re-reads the config, for long-running sessions without a prompt.
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+	go func() {
+		for range reloadChan {
+			mixer.ReloadConfig(*configPath)
 		}
+	}()
+
+	libraryReady, libraryDone := mixer.LoadLibrary(audioFiles, *loadWorkers)
+	<-libraryReady
+	// A session to resume and piped scripts refer to instruments by name, so
+	// they wait for the whole library; a terminal can start typing now.
+	if *resume || !stdinIsTerminal() {
+		<-libraryDone
 	}
 
-	speaker.Play(&mixer.mixer)
+	if *resume {
+		mixer.resumeSession()
+	}
+	if *followTempo {
+		mixer.SetTempoFollow(true)
+	}
 
-	go runCommandLoop(mixer)
+	mixer.playMaster()
+
+	if *tcpAddr != "" {
+		go func() {
+			if err := serveTCP(mixer, *tcpAddr); err != nil {
+				log.Printf("❌ Servidor TCP encerrado: %v", err)
+			}
+		}()
+	}
+
+	inputDone := make(chan struct{})
+	go func() {
+		runCommandLoop(mixer)
+		close(inputDone)
+	}()
+	var eof <-chan struct{} // nil, never ready, unless we exit at end of input
+	if shouldExitOnEOF() {
+		eof = inputDone
+	}
+
+	select {
+	case <-shutdownChan:
+		log.Println("\n👋 Sinal de interrupção recebido. Desligando graciosamente...")
+	case <-eof:
+		log.Println("👋 Fim da entrada de comandos. Desligando...")
+	}
+	// Saved before the deferred Close stops every instrument.
+	if *autosave {
+		mixer.autosaveSession()
+	}
+}
+
+// shouldExitOnEOF honours an explicit --exit-on-eof; otherwise piped scripts
+// exit when they run out while an interactive terminal keeps the music going.
+func shouldExitOnEOF() bool {
+	if flagSet("exit-on-eof") {
+		return *exitOnEOF
+	}
+	return !stdinIsTerminal()
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	explicit := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			explicit = true
+		}
+	})
+	return explicit
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
-	<-shutdownChan
+// supportedSampleRates are the output rates --rate accepts: the standard rates
+// sound cards run at.
+var supportedSampleRates = []int{8000, 11025, 16000, 22050, 32000, 44100, 48000, 88200, 96000, 176400, 192000}
 
-	log.Println("\n👋 Sinal de interrupção recebido. Desligando graciosamente...")
+// validateSampleRate checks rate against the standard rates up front; whether
+// the device really takes it is only known when the speaker opens.
+func validateSampleRate(rate int) error {
+	for _, r := range supportedSampleRates {
+		if r == rate {
+			return nil
+		}
+	}
+	return errorf(ErrOutOfRange, "taxa de amostragem %d Hz não suportada (use uma de %v)", rate, supportedSampleRates)
 }
 
 func getSampleRateFromFile(filename string) (beep.SampleRate, error) {
@@ -302,133 +869,1315 @@ func getSampleRateFromFile(filename string) (beep.SampleRate, error) {
 
 func runCommandLoop(dj *DJMixer) {
 	scanner := bufio.NewScanner(os.Stdin)
-	printHelp()
+	interactive := stdinIsTerminal()
+	if interactive {
+		printHelp(os.Stdout)
+	}
 	for {
-		fmt.Print("> ")
+		if interactive {
+			fmt.Print(dj.renderPrompt())
+		}
 		if !scanner.Scan() {
 			if err := scanner.Err(); err != nil && err != context.Canceled {
 				log.Printf("❌ Erro ao ler entrada: %v", err)
 			}
 			return
 		}
-		handleCommand(dj, scanner.Text())
+		line := scanner.Text()
+		// Hotkey mode takes over the terminal, so only the local prompt offers it.
+		if interactive && strings.EqualFold(strings.TrimSpace(line), "keys") {
+			if err := runHotkeys(dj, os.Stdin, os.Stdout); err != nil {
+				log.Printf("❌ Erro: %v", err)
+			}
+			continue
+		}
+		if err := handleCommand(dj, os.Stdout, line); errors.Is(err, ErrHeld) {
+			log.Printf("⏳ %v", err)
+		} else if err != nil {
+			log.Printf("❌ Erro: %v", err)
+		}
 	}
 }
 
-func handleCommand(dj *DJMixer, input string) {
+// handleCommand parses and executes a single text command against the mixer.
+// Command output (list, help) is written to out; failures are returned so each
+// front-end (stdin, TCP) can report them in its own way. A panic while running
+// the command is recovered, written to the panic log and returned as an error.
+func handleCommand(dj *DJMixer, out io.Writer, input string) (err error) {
 	input = strings.TrimSpace(input)
-	if input == "" {
-		return
+	if input == "" || strings.HasPrefix(input, "#") {
+		// Lines starting with # are comments, as in scripts and transcripts.
+		return nil
 	}
+	dj.mu.RLock()
+	done := dj.transcript.record(input)
+	dj.mu.RUnlock()
+	defer done()
+	defer func() {
+		if v := recover(); v != nil {
+			err = reportPanic(fmt.Sprintf("comando '%s'", input), v, debug.Stack())
+		}
+	}()
+	if input, err = dj.expandBinding(input); err != nil {
+		return err
+	}
+	input = dj.expandAlias(input)
+	if dj.throttle.hold(input, out) {
+		return ErrHeld
+	}
+	return runCommand(dj, out, input)
+}
+
+// runCommand executes a command whose alias has already been expanded.
+func runCommand(dj *DJMixer, out io.Writer, input string) (err error) {
 	parts := strings.Fields(strings.ToLower(input))
+	rawParts := strings.Fields(input) // original case, for file paths
 	cmd := parts[0]
-	var err error
 	switch cmd {
 	case "play", "start", "pause", "stop", "replay":
-		target := ""
+		action := transportActions[cmd]
 		if len(parts) > 1 {
-			target = parts[1]
-		}
-		var action func(i *Instrument) error
-		if cmd == "play" || cmd == "start" {
-			action = func(i *Instrument) error { return i.Play() }
-		} else if cmd == "pause" {
-			action = func(i *Instrument) error { return i.Pause() }
-		} else if cmd == "stop" {
-			action = func(i *Instrument) error { return i.Stop() }
-		} else if cmd == "replay" {
-			action = func(i *Instrument) error { return i.Replay() }
-		}
-		dj.mu.RLock()
-		if target != "" {
-			if inst, ok := dj.instruments[target]; ok {
-				err = action(inst)
+			target := parts[1]
+			if inst, ok := dj.GetInstrument(target); ok {
+				if err = action(inst); err == nil && (cmd == "play" || cmd == "start" || cmd == "replay") {
+					dj.choke(inst)
+					dj.followTempo(inst)
+				}
 			} else {
-				err = fmt.Errorf("instrumento '%s' não encontrado", target)
+				err = instrumentNotFound(target)
 			}
+		} else if *bareAll {
+			dj.forEachTransport(action)
 		} else {
-			for _, inst := range dj.instruments {
-				if e := action(inst); e != nil {
-					log.Printf("⚠️  Ignorando erro na operação em lote para '%s': %v", inst.name, e)
-				}
+			batch := cmd
+			if batch == "start" {
+				batch = "play"
+			}
+			return fmt.Errorf("informe um instrumento ou use '%sall' para todos", batch)
+		}
+	case "arm":
+		if len(parts) < 2 {
+			if names := dj.ArmedNames(); len(names) > 0 {
+				fmt.Fprintf(out, "Armados: %s\n", strings.Join(names, ", "))
+			} else {
+				fmt.Fprintln(out, "Nenhum instrumento armado.")
 			}
+			return nil
+		}
+		if parts[1] == "off" {
+			dj.Disarm()
+			return nil
 		}
-		dj.mu.RUnlock()
+		err = dj.Arm(parts[1:])
+	case "go":
+		err = dj.Go()
+	case "exclude", "include":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: %s <instrumento>", cmd)
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			inst.SetExcludeFromAll(cmd == "exclude")
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "playall", "pauseall", "stopall", "replayall":
+		dj.forEachTransport(transportActions[strings.TrimSuffix(cmd, "all")])
 	case "volume", "vol":
 		if len(parts) < 3 {
-			log.Println("❌ Uso: volume <instrumento> <valor>")
-			return
+			return fmt.Errorf("uso: volume <instrumento|all> <valor|+delta>")
 		}
 		target, valStr := parts[1], parts[2]
-		vol, parseErr := strconv.ParseFloat(valStr, 64)
+		// A leading + makes the value a change from the current volume ("+0.1",
+		// "+-0.1"); a bare negative number is still an absolute level.
+		relative := strings.HasPrefix(valStr, "+")
+		vol, parseErr := parseFloatArg("volume", strings.TrimPrefix(valStr, "+"))
 		if parseErr != nil {
-			log.Printf("❌ Valor de volume inválido: %s", valStr)
-			return
+			return parseErr
 		}
-		if inst, ok := dj.GetInstrument(target); ok {
-			err = inst.SetVolume(vol)
+		setVolume := func(inst *Instrument) error {
+			if relative {
+				inst.AdjustVolume(vol)
+				return nil
+			}
+			return inst.SetVolume(vol)
+		}
+		if target == "all" {
+			prev := make(map[*Instrument]float64)
+			dj.ForEachInstrument(func(inst *Instrument) error {
+				prev[inst] = inst.Volume()
+				return setVolume(inst)
+			})
+			dj.undo.Push("volume de todos", func() error {
+				for inst, v := range prev {
+					if e := inst.SetVolume(v); e != nil {
+						return e
+					}
+				}
+				return nil
+			})
+		} else if inst, ok := dj.GetInstrument(target); ok {
+			prev := make(map[*Instrument]float64)
+			for _, member := range dj.linked(inst) {
+				v := member.Volume()
+				if err = setVolume(member); err != nil {
+					break
+				}
+				prev[member] = v
+			}
+			if len(prev) > 0 {
+				dj.undo.Push(fmt.Sprintf("volume de '%s'", inst.name), func() error {
+					for member, v := range prev {
+						if e := member.SetVolume(v); e != nil {
+							return e
+						}
+					}
+					return nil
+				})
+			}
 		} else {
-			err = fmt.Errorf("instrumento '%s' não encontrado", target)
+			err = instrumentNotFound(target)
 		}
 	case "bpm":
 		if len(parts) < 3 {
-			log.Println("❌ Uso: bpm <instrumento> <valor>")
-			return
+			return fmt.Errorf("uso: bpm <instrumento> <valor>")
 		}
 		target, valStr := parts[1], parts[2]
-		targetBPM, parseErr := strconv.ParseFloat(valStr, 64)
-		if parseErr != nil || targetBPM <= 0 {
-			log.Printf("❌ Valor de BPM inválido: %s", valStr)
-			return
+		targetBPM, parseErr := parseRange("BPM", valStr, BaseBPM*MinSpeedRatio, BaseBPM*MaxSpeedRatio)
+		if parseErr != nil {
+			return parseErr
 		}
+		dj.cancelTempoRamp()
 		if inst, ok := dj.GetInstrument(target); ok {
 			ratio := targetBPM / BaseBPM
-			err = inst.SetSpeed(ratio)
+			prev := make(map[*Instrument]float64)
+			for _, member := range dj.linked(inst) {
+				r := member.SpeedRatio()
+				if err = member.SetSpeed(ratio); err != nil {
+					break
+				}
+				prev[member] = r
+			}
+			if len(prev) > 0 {
+				dj.undo.Push(fmt.Sprintf("BPM de '%s'", inst.name), func() error {
+					for member, r := range prev {
+						if e := member.SetSpeed(r); e != nil {
+							return e
+						}
+					}
+					return nil
+				})
+			}
+		} else {
+			err = instrumentNotFound(target)
+		}
+	case "masterbpm":
+		if len(parts) < 2 {
+			fmt.Fprintf(out, "BPM mestre: %.1f\n", dj.MasterBPM())
+			return nil
+		}
+		bpm, parseErr := parseRange("BPM mestre", parts[1], BaseBPM*MinSpeedRatio, BaseBPM*MaxSpeedRatio)
+		if parseErr != nil {
+			return parseErr
+		}
+		dj.cancelTempoRamp()
+		err = dj.SetMasterBPM(bpm)
+	case "beatvis":
+		if len(parts) < 2 || (parts[1] != "on" && parts[1] != "off") {
+			return fmt.Errorf("uso: beatvis on|off")
+		}
+		err = dj.SetBeatVisual(parts[1] == "on")
+	case "xfader":
+		switch {
+		case len(parts) < 2:
+			printCrossfader(out, dj.Crossfader())
+		case parts[1] == "assign":
+			if len(parts) < 4 {
+				return fmt.Errorf("uso: xfader assign <instrumentoA> <instrumentoB>")
+			}
+			err = dj.AssignDecks(parts[2], parts[3])
+		case parts[1] == "off":
+			err = dj.ClearDecks()
+		default:
+			pos, parseErr := parseRange("posição do crossfader (0 = A, 1 = B)", parts[1], 0, 1)
+			if parseErr != nil {
+				return parseErr
+			}
+			err = dj.SetCrossfader(pos)
+		}
+	case "xfadercurve":
+		if len(parts) < 2 {
+			fmt.Fprintf(out, "Curva do crossfader: %s\n", dj.Crossfader().curve)
+			return nil
+		}
+		curve, parseErr := parseXfaderCurve(parts[1])
+		if parseErr != nil {
+			return parseErr
+		}
+		dj.SetCrossfaderCurve(curve)
+	case "swing":
+		if len(parts) < 2 {
+			fmt.Fprintf(out, "Swing: %.0f%%\n", dj.Swing())
+			return nil
+		}
+		percent, parseErr := parseRange("swing", strings.TrimSuffix(parts[1], "%"), 0, MaxSwing)
+		if parseErr != nil {
+			return parseErr
+		}
+		err = dj.SetSwing(percent)
+	case "metronome":
+		if len(parts) < 2 || (parts[1] != "on" && parts[1] != "off") {
+			return fmt.Errorf("uso: metronome on|off")
+		}
+		err = dj.SetMetronome(parts[1] == "on")
+	case "offset":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: offset <instrumento> [batidas]")
+		}
+		inst, ok := dj.GetInstrument(parts[1])
+		if !ok {
+			return instrumentNotFound(parts[1])
+		}
+		if len(parts) < 3 {
+			fmt.Fprintf(out, "Offset de '%s': %.2f batida(s)\n", inst.name, inst.Offset())
+			return nil
+		}
+		beats, parseErr := parseFloatArg("offset em batidas", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		err = inst.SetOffset(beats)
+	case "jump":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: jump <instrumento> <batidas>")
+		}
+		beats, parseErr := parseFloatArg("salto em batidas", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.BeatJump(beats, dj.MasterBPM())
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "cue":
+		if len(parts) < 4 || (parts[1] != "set" && parts[1] != "jump" && parts[1] != "clear") {
+			return fmt.Errorf("uso: cue set|jump|clear <instrumento> <1-%d>", MaxHotCues)
+		}
+		slot, parseErr := parseIntRange("cue", parts[3], 1, MaxHotCues)
+		if parseErr != nil {
+			return parseErr
+		}
+		inst, ok := dj.GetInstrument(parts[2])
+		if !ok {
+			return instrumentNotFound(parts[2])
+		}
+		switch parts[1] {
+		case "set":
+			err = inst.SetCue(slot)
+		case "jump":
+			if err = inst.JumpToCue(slot); err == nil {
+				dj.choke(inst)
+			}
+		case "clear":
+			err = inst.ClearCue(slot)
+		}
+	case "tempo-follow":
+		if len(parts) < 2 {
+			fmt.Fprintf(out, "Tempo follow: %s\n", onOff(dj.TempoFollow()))
+			return nil
+		}
+		if parts[1] != "on" && parts[1] != "off" {
+			return fmt.Errorf("uso: tempo-follow [on|off]")
+		}
+		dj.SetTempoFollow(parts[1] == "on")
+	case "bind":
+		err = handleBindCommand(dj, out, parts[1:])
+	case "stop-mode":
+		if len(parts) < 2 {
+			fmt.Fprintf(out, "Modo de parada: %s\n", stopMode())
+			return nil
+		}
+		m, parseErr := parseStopMode(parts[1])
+		if parseErr != nil {
+			return parseErr
+		}
+		dj.SetStopMode(m)
+	case "pattern":
+		switch {
+		case len(parts) == 1:
+			dj.printPatterns(out)
+		case len(parts) < 3:
+			return fmt.Errorf("uso: pattern <instrumento> <x.x.x.x.>|off")
+		case parts[2] == "off":
+			err = dj.StopPattern(parts[1])
+		default:
+			err = dj.SetPattern(parts[1], parts[2])
+		}
+	case "roll":
+		switch {
+		case len(parts) == 3 && parts[2] == "off":
+			err = dj.StopRoll(parts[1])
+		case len(parts) < 5:
+			return fmt.Errorf("uso: roll <instrumento> <divisãoInicial> <divisãoFinal> <compassos> | roll <instrumento> off")
+		default:
+			var vals [3]int
+			for k, valStr := range parts[2:5] {
+				what, lo, hi := rollArgs[k], MinRollDivision, MaxRollDivision
+				if k == 2 {
+					lo, hi = 1, MaxRollBars
+				}
+				v, parseErr := parseIntRange(what, valStr, lo, hi)
+				if parseErr != nil {
+					return parseErr
+				}
+				vals[k] = v
+			}
+			err = dj.Roll(parts[1], vals[0], vals[1], vals[2])
+		}
+	case "align":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: align <instrumento>")
+		}
+		err = dj.Align(parts[1])
+	case "ramp":
+		if len(parts) < 4 || parts[1] != "bpm" {
+			return fmt.Errorf("uso: ramp bpm <alvo> <segundos>")
+		}
+		target, parseErr := parseRange("BPM alvo", parts[2], BaseBPM*MinSpeedRatio, BaseBPM*MaxSpeedRatio)
+		if parseErr != nil {
+			return parseErr
+		}
+		secs, parseErr := parseFloatArg("duração da rampa", parts[3])
+		if parseErr != nil {
+			return parseErr
+		}
+		err = dj.RampMasterBPM(target, time.Duration(secs*float64(time.Second)))
+	case "flanger":
+		if len(parts) < 5 {
+			return fmt.Errorf("uso: flanger <instrumento> <taxaHz> <profundidade> <realimentação>")
+		}
+		var vals [3]float64
+		for k, what := range []string{"taxa do flanger", "profundidade do flanger", "realimentação do flanger"} {
+			v, parseErr := parseFloatArg(what, parts[k+2])
+			if parseErr != nil {
+				return parseErr
+			}
+			vals[k] = v
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetFlanger(vals[0], vals[1], vals[2])
 		} else {
-			err = fmt.Errorf("instrumento '%s' não encontrado", target)
+			err = instrumentNotFound(parts[1])
 		}
+	case "chorus":
+		if len(parts) < 5 {
+			return fmt.Errorf("uso: chorus <instrumento> <taxaHz> <profundidade> <vozes>")
+		}
+		rate, parseErr := parseFloatArg("taxa do chorus", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		depth, parseErr := parseFloatArg("profundidade do chorus", parts[3])
+		if parseErr != nil {
+			return parseErr
+		}
+		voices, parseErr := parseIntRange("vozes do chorus", parts[4], 1, chorusMaxVoices)
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetChorus(rate, depth, voices)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "drive":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: drive <instrumento> <quantidade>")
+		}
+		amount, parseErr := parseFloatArg("quantidade de drive", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetDrive(amount)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "trim-silence":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: trim-silence <instrumento> [dB|off]")
+		}
+		inst, ok := dj.GetInstrument(parts[1])
+		if !ok {
+			return instrumentNotFound(parts[1])
+		}
+		switch {
+		case len(parts) > 2 && parts[2] == "off":
+			err = inst.ClearLoopRegion()
+		case len(parts) > 2:
+			db, parseErr := parseFloatArg("limiar de silêncio", parts[2])
+			if parseErr != nil {
+				return parseErr
+			}
+			err = inst.TrimSilence(db)
+		default:
+			err = inst.TrimSilence(*silenceDBFS)
+		}
+	case "lowpass":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: lowpass <instrumento> <hz|off>")
+		}
+		freq := 0.0
+		if parts[2] != "off" {
+			var parseErr error
+			if freq, parseErr = parseFloatArg("frequência de corte", parts[2]); parseErr != nil {
+				return parseErr
+			}
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetLowPass(freq)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "autofilter":
+		switch {
+		case len(parts) == 3 && parts[2] == "off":
+			err = dj.StopAutoFilter(parts[1])
+		case len(parts) < 3:
+			return fmt.Errorf("uso: autofilter <instrumento> <compassos> [profundidade] [triangle|sine|saw] | autofilter <instrumento> off")
+		default:
+			p := autoFilterParams{Depth: 1}
+			bars, parseErr := parseIntRange("compassos do autofilter", parts[2], 1, MaxAutoFilterBars)
+			if parseErr != nil {
+				return parseErr
+			}
+			p.Bars = bars
+			if len(parts) > 3 {
+				if p.Depth, parseErr = parseFloatArg("profundidade do autofilter", parts[3]); parseErr != nil {
+					return parseErr
+				}
+			}
+			if len(parts) > 4 {
+				if p.Shape, err = parseFilterShape(parts[4]); err != nil {
+					return err
+				}
+			}
+			err = dj.AutoFilter(parts[1], p)
+		}
+	case "width":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: width <instrumento> <fator>")
+		}
+		factor, parseErr := parseFloatArg("fator de largura", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetWidth(factor)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "duck":
+		err = handleDuckCommand(dj, parts[1:])
+	case "swaplr":
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: swaplr <instrumento> on|off")
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			inst.SetSwapLR(parts[2] == "on")
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "phaseinvert":
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: phaseinvert <instrumento> on|off")
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			inst.SetPhaseInvert(parts[2] == "on")
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "chanvol":
+		if len(parts) < 4 {
+			return fmt.Errorf("uso: chanvol <instrumento> left|right <valor>")
+		}
+		channel, chErr := parseChannel(parts[2])
+		if chErr != nil {
+			return chErr
+		}
+		gain, parseErr := parseFloatArg("ganho de canal", parts[3])
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetChannelGain(channel, gain)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "chanmute":
+		if len(parts) < 3 || (len(parts) > 3 && parts[3] != "on" && parts[3] != "off") {
+			return fmt.Errorf("uso: chanmute <instrumento> left|right [on|off]")
+		}
+		channel, chErr := parseChannel(parts[2])
+		if chErr != nil {
+			return chErr
+		}
+		muted := len(parts) < 4 || parts[3] == "on"
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetChannelMute(channel, muted)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "reset":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: reset <instrumento>|all")
+		}
+		if parts[1] == "all" {
+			for _, inst := range dj.GetAllInstrumentsSorted() {
+				if e := inst.Reset(); e != nil {
+					log.Printf("⚠️  Ignorando erro na operação em lote para '%s': %v", inst.name, e)
+				}
+			}
+		} else if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.Reset()
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "repeat":
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: repeat <instrumento> on|off")
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			inst.SetRepeat(parts[2] == "on")
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "reload":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: reload <instrumento>")
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.Reload()
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "detune":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: detune <instrumento> <cents>")
+		}
+		cents, parseErr := parseFloatArg("detune em cents", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			inst.SetDetune(cents)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "poly":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: poly <instrumento> <vozes> (1 desliga)")
+		}
+		voices, parseErr := parseIntRange("número de vozes", parts[2], 1, MaxPolyVoices)
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetPolyphony(voices)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "quality":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: quality <instrumento> <%d-%d>", MinResampleQuality, MaxResampleQuality)
+		}
+		q, parseErr := parseIntRange("qualidade", parts[2], MinResampleQuality, MaxResampleQuality)
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetResampleQuality(q)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "retrigger":
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: retrigger <instrumento> on|off")
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			inst.SetRetrigger(parts[2] == "on")
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "loop":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: loop <instrumento> <vezes>|inf")
+		}
+		count := -1
+		if parts[2] != "inf" {
+			n, parseErr := parseIntArg("número de repetições", parts[2])
+			if parseErr != nil {
+				return parseErr
+			}
+			count = n
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetLoopCount(count)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "fade":
+		if len(parts) < 4 || (parts[2] != "in" && parts[2] != "out") {
+			return fmt.Errorf("uso: fade <instrumento> in|out <segundos> [linear|log|equal]")
+		}
+		secs, parseErr := parseFloatArg("duração do fade", parts[3])
+		if parseErr != nil {
+			return parseErr
+		}
+		curve := dj.FadeCurve()
+		if len(parts) > 4 {
+			if curve, err = parseFadeCurve(parts[4]); err != nil {
+				return err
+			}
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.Fade(parts[2] == "in", time.Duration(secs*float64(time.Second)), curve)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "fadecurve":
+		if len(parts) < 2 {
+			fmt.Fprintf(out, "Curva de fade padrão: %s\n", dj.FadeCurve())
+			return nil
+		}
+		curve, curveErr := parseFadeCurve(parts[1])
+		if curveErr != nil {
+			return curveErr
+		}
+		dj.SetFadeCurve(curve)
+	case "humanize":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: humanize <instrumento> <ms>")
+		}
+		ms, parseErr := parseFloatArg("humanize em ms", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetHumanize(time.Duration(ms * float64(time.Millisecond)))
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "playfrom":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: playfrom <instrumento> <segundos>")
+		}
+		secs, parseErr := parseFloatArg("posição em segundos", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			if err = inst.PlayFrom(time.Duration(secs * float64(time.Second))); err == nil {
+				dj.choke(inst)
+			}
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "trim":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: trim <instrumento> <dB>")
+		}
+		db, parseErr := parseRange("trim em dB", parts[2], MinTrimDB, MaxTrimDB)
+		if parseErr != nil {
+			return parseErr
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			err = inst.SetTrim(db)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "testtone":
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: testtone <freqHz> on|off")
+		}
+		freq, parseErr := parseFloatArg("frequência do tom de teste", parts[1])
+		if parseErr != nil {
+			return parseErr
+		}
+		err = dj.SetTestTone(freq, parts[2] == "on")
+	case "noise":
+		if len(parts) == 2 && parts[1] == "off" {
+			err = dj.SetNoise("", false)
+			break
+		}
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: noise white|pink on|off")
+		}
+		err = dj.SetNoise(parts[1], parts[2] == "on")
+	case "freeze":
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: freeze <instrumento> on|off")
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			inst.SetFreeze(parts[2] == "on")
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "limits":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: limits <instrumento> [<volMin> <volMax> <velMin> <velMax> | reset]")
+		}
+		inst, ok := dj.GetInstrument(parts[1])
+		if !ok {
+			return instrumentNotFound(parts[1])
+		}
+		switch {
+		case len(parts) == 2:
+			l := inst.Limits()
+			fmt.Fprintf(out, "Limites de '%s': volume [%.2f, %.2f], velocidade [%.2fx, %.2fx]\n", inst.name, l.MinVolume, l.MaxVolume, l.MinSpeed, l.MaxSpeed)
+		case parts[2] == "reset":
+			inst.ResetLimits()
+		case len(parts) == 6:
+			var vals [4]float64
+			for k, valStr := range parts[2:6] {
+				what, lo, hi := limitArgs[k], MinVolume, MaxVolume
+				if k >= 2 {
+					lo, hi = MinSpeedRatio, MaxSpeedRatio
+				}
+				v, parseErr := parseRange(what, valStr, lo, hi)
+				if parseErr != nil {
+					return parseErr
+				}
+				vals[k] = v
+			}
+			err = inst.SetLimits(vals[0], vals[1], vals[2], vals[3])
+		default:
+			return fmt.Errorf("uso: limits <instrumento> [<volMin> <volMax> <velMin> <velMax> | reset]")
+		}
+	case "chokegroup":
+		switch {
+		case len(parts) < 2:
+			printChokeGroups(dj, out)
+		case len(parts) == 3 && parts[2] == "off":
+			err = dj.ClearChokeGroup(parts[1])
+		case len(parts) < 3:
+			return fmt.Errorf("uso: chokegroup <grupo> <nomes...> | chokegroup <grupo> off")
+		default:
+			err = dj.SetChokeGroup(parts[1], parts[2:])
+		}
+	case "link":
+		if len(parts) < 2 {
+			printLinks(dj, out)
+			return nil
+		}
+		err = dj.Link(parts[1:])
+	case "unlink":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: unlink <instrumento>")
+		}
+		err = dj.Unlink(parts[1])
+	case "wait":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: wait <segundos>")
+		}
+		secs, parseErr := parseFloatArg("tempo de espera", parts[1])
+		if parseErr != nil || secs < 0 {
+			return fmt.Errorf("tempo inválido: %s", parts[1])
+		}
+		time.Sleep(time.Duration(secs * float64(time.Second)))
+	case "sleep":
+		if len(parts) < 2 {
+			if remaining, ok := dj.SleepRemaining(); ok {
+				fmt.Fprintf(out, "Timer: %s restantes.\n", remaining.Round(time.Second))
+			} else {
+				fmt.Fprintln(out, "Nenhum timer ativo.")
+			}
+			return nil
+		}
+		if parts[1] == "cancel" {
+			if !dj.CancelSleep() {
+				return fmt.Errorf("nenhum timer ativo")
+			}
+			log.Println("😴 Timer cancelado.")
+			return nil
+		}
+		mins, parseErr := parseFloatArg("timer em minutos", parts[1])
+		if parseErr != nil {
+			return parseErr
+		}
+		err = dj.Sleep(time.Duration(mins * float64(time.Minute)))
+	case "playlist", "pl":
+		err = handlePlaylistCommand(dj, out, parts[1:])
+	case "undo", "u":
+		err = dj.Undo()
+	case "scene":
+		err = handleSceneCommand(dj, out, parts[1:])
+	case "preset":
+		err = handlePresetCommand(dj, out, parts[1:])
+	case "ab":
+		err = handleABCommand(dj, parts[1:])
+	case "captureloop":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: captureloop <compassos> [nome]")
+		}
+		bars, parseErr := parseIntRange("duração da captura em compassos", parts[1], 1, MaxCaptureBars)
+		if parseErr != nil {
+			return parseErr
+		}
+		name := ""
+		if len(parts) > 2 {
+			name = parts[2]
+		}
+		err = dj.CaptureLoop(bars, name)
+	case "dump":
+		fmt.Fprint(out, dj.DumpScript())
+	case "session":
+		if len(parts) < 2 || (parts[1] != "save" && parts[1] != "load") {
+			return fmt.Errorf("uso: session save|load [arquivo]")
+		}
+		path := DefaultSessionFile
+		if len(rawParts) > 2 {
+			path = rawParts[2]
+		}
+		if parts[1] == "save" {
+			err = dj.SaveSession(path)
+		} else {
+			err = dj.LoadSession(path)
+		}
+	case "status":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: status <instrumento>")
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			printInstrumentStatus(out, inst)
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "waveform", "wave":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: waveform <instrumento> [colunas]")
+		}
+		buckets := 64
+		if len(parts) > 2 {
+			n, parseErr := parseIntRange("número de colunas", parts[2], 1, MaxWaveformBuckets)
+			if parseErr != nil {
+				return parseErr
+			}
+			buckets = n
+		}
+		inst, ok := dj.GetInstrument(parts[1])
+		if !ok {
+			return instrumentNotFound(parts[1])
+		}
+		wave, waveErr := inst.Waveform(buckets)
+		if waveErr != nil {
+			return fmt.Errorf("falha ao gerar a forma de onda de '%s': %w", inst.name, waveErr)
+		}
+		printWaveform(out, inst.name, wave)
+	case "history":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: history <instrumento>")
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			fmt.Fprintf(out, "%s %s\n", inst.name, sparkline(inst.LevelHistory()))
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "mono":
+		if len(parts) < 2 || (parts[1] != "on" && parts[1] != "off") {
+			return fmt.Errorf("uso: mono on|off")
+		}
+		dj.SetMono(parts[1] == "on")
+	case "mastervol":
+		if len(parts) < 2 {
+			fmt.Fprintf(out, "Volume mestre: %+.2f\n", dj.MasterVolume())
+			return nil
+		}
+		vol, parseErr := parseRange("volume mestre", parts[1], MinVolume, MaxVolume)
+		if parseErr != nil {
+			return parseErr
+		}
+		err = dj.SetMasterVolume(vol)
+	case "mastereq":
+		if len(parts) < 2 {
+			g := dj.MasterEQ()
+			fmt.Fprintf(out, "EQ mestre: graves %+.1f dB, médios %+.1f dB, agudos %+.1f dB\n", g[EQLow], g[EQMid], g[EQHigh])
+			return nil
+		}
+		var gains [3]float64
+		if len(parts) == 2 && parts[1] == "off" {
+			err = dj.SetMasterEQ(gains)
+			break
+		}
+		if len(parts) < 4 {
+			return fmt.Errorf("uso: mastereq <graves> <médios> <agudos> (dB) | off")
+		}
+		for b := range gains {
+			db, parseErr := parseRange("ganho de "+eqBandNames[b], parts[b+1], MinEQDB, MaxEQDB)
+			if parseErr != nil {
+				return parseErr
+			}
+			gains[b] = db
+		}
+		err = dj.SetMasterEQ(gains)
+	case "kill":
+		if len(parts) < 2 {
+			killed := dj.KilledBands()
+			for b, name := range eqBandArgs {
+				fmt.Fprintf(out, "%-5s %s\n", name, onOff(killed[b]))
+			}
+			return nil
+		}
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: kill low|mid|high on|off")
+		}
+		band, parseErr := parseEQBand(parts[1])
+		if parseErr != nil {
+			return parseErr
+		}
+		err = dj.SetKill(band, parts[2] == "on")
+	case "masterreverb", "masterdelay", "mastercomp", "masterlimit", "rack":
+		err = handleRackCommand(dj, out, parts)
+	case "clip":
+		if len(parts) < 2 {
+			fmt.Fprintf(out, "Indicador de clip: a partir de %.1f dBFS\n", dj.ClipThreshold())
+			return nil
+		}
+		db, parseErr := parseRange("limiar de clip em dB", parts[1], MinClipDB, MaxClipDB)
+		if parseErr != nil {
+			return parseErr
+		}
+		err = dj.SetClipThreshold(db)
+	case "dim":
+		if len(parts) < 2 || (parts[1] != "on" && parts[1] != "off") {
+			return fmt.Errorf("uso: dim on|off")
+		}
+		err = dj.SetDim(parts[1] == "on")
+	case "preview":
+		if len(parts) < 4 {
+			return fmt.Errorf("uso: preview <instrumento> <segundos> <duraçãoMs>")
+		}
+		secs, parseErr := parseFloatArg("posição em segundos", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		ms, parseErr := parseFloatArg("duração em ms", parts[3])
+		if parseErr != nil {
+			return parseErr
+		}
+		err = dj.Preview(parts[1], time.Duration(secs*float64(time.Second)), time.Duration(ms*float64(time.Millisecond)))
+	case "mixdown":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: mixdown <arquivo.wav> <segundos>")
+		}
+		secs, parseErr := parseFloatArg("duração do mixdown", parts[2])
+		if parseErr != nil {
+			return parseErr
+		}
+		err = dj.Mixdown(rawParts[1], time.Duration(secs*float64(time.Second)))
+	case "solo":
+		if len(parts) == 2 && parts[1] == "off" {
+			dj.ClearSolo()
+			return nil
+		}
+		if len(parts) < 2 || (len(parts) > 2 && parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: solo <instrumento> [on|off] | solo off")
+		}
+		err = dj.Solo(parts[1], len(parts) == 2 || parts[2] == "on")
+	case "solosafe":
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return fmt.Errorf("uso: solosafe <instrumento> on|off")
+		}
+		if inst, ok := dj.GetInstrument(parts[1]); ok {
+			inst.SetSoloSafe(parts[2] == "on")
+			dj.applySolo()
+		} else {
+			err = instrumentNotFound(parts[1])
+		}
+	case "enable", "disable":
+		if len(parts) < 2 {
+			return fmt.Errorf("uso: %s <instrumento>", cmd)
+		}
+		err = dj.SetEnabled(parts[1], cmd == "enable")
+	case "load":
+		if len(parts) < 3 {
+			return fmt.Errorf("uso: load <nome> <arquivo|url>")
+		}
+		err = dj.Load(parts[1], rawParts[2])
+	case "files":
+		err = listMusicFiles(dj, out, *musicDir)
+	case "statusjson":
+		data, jsonErr := dj.StatusJSON()
+		if jsonErr != nil {
+			return fmt.Errorf("falha ao gerar JSON: %w", jsonErr)
+		}
+		fmt.Fprintln(out, string(data))
+	case "keys":
+		return fmt.Errorf("o modo de teclas só está disponível no terminal interativo")
 	case "list", "ls":
-		listInstruments(dj)
+		listInstruments(dj, out)
+	case "np":
+		printNowPlaying(dj, out)
+	case "reinit-audio":
+		err = dj.ReinitAudio()
 	case "help", "h":
-		printHelp()
+		if len(parts) > 1 {
+			return printCommandHelp(dj, out, parts[1])
+		}
+		printHelp(out)
+	case "version":
+		printVersion(out)
+	case "rate":
+		fmt.Fprintf(out, "Taxa de amostragem da saída: %d Hz\n", dj.sampleRate)
+		for _, inst := range dj.GetAllInstrumentsSorted() {
+			if fileRate := inst.format.SampleRate; fileRate != dj.sampleRate {
+				fmt.Fprintf(out, "  %-10s %d Hz (reamostrado)\n", inst.name, fileRate)
+			}
+		}
 	case "quit", "exit", "q":
 		log.Println("Use Ctrl+C para sair.")
 		p, _ := os.FindProcess(os.Getpid())
 		_ = p.Signal(os.Interrupt)
 	default:
-		log.Printf("❓ Comando desconhecido: '%s'. Digite 'help' para ver as opções.", cmd)
+		return fmt.Errorf("comando desconhecido: '%s'. Digite 'help' para ver as opções", cmd)
 	}
-	if err != nil {
-		log.Printf("❌ Erro: %v", err)
+	return err
+}
+
+func handleSceneCommand(dj *DJMixer, out io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("uso: scene save|recall|list [nome] [segundos]")
 	}
+	switch args[0] {
+	case "save":
+		if len(args) < 2 {
+			return fmt.Errorf("uso: scene save <nome>")
+		}
+		dj.SaveScene(args[1])
+		return nil
+	case "recall":
+		if len(args) < 2 {
+			return fmt.Errorf("uso: scene recall <nome> [segundos]")
+		}
+		var transition time.Duration
+		if len(args) > 2 {
+			secs, parseErr := parseFloatArg("tempo de transição", args[2])
+			if parseErr != nil || secs < 0 {
+				return fmt.Errorf("tempo de transição inválido: %s", args[2])
+			}
+			transition = time.Duration(secs * float64(time.Second))
+		}
+		return dj.RecallScene(args[1], transition)
+	case "list", "ls":
+		fmt.Fprintln(out, "--- Cenas ---")
+		for _, name := range dj.SceneNames() {
+			fmt.Fprintf(out, " 🎬 %s\n", name)
+		}
+		fmt.Fprintln(out, "-------------")
+		return nil
+	default:
+		return fmt.Errorf("subcomando de cena desconhecido: '%s'", args[0])
+	}
+}
+
+func handlePresetCommand(dj *DJMixer, out io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("uso: preset save|apply|list [nome] [instrumento]")
+	}
+	switch args[0] {
+	case "save":
+		if len(args) < 3 {
+			return fmt.Errorf("uso: preset save <nome> <instrumento>")
+		}
+		return dj.SavePreset(args[1], args[2])
+	case "apply":
+		if len(args) < 3 {
+			return fmt.Errorf("uso: preset apply <nome> <instrumento>")
+		}
+		return dj.ApplyPreset(args[1], args[2])
+	case "list", "ls":
+		fmt.Fprintln(out, "--- Presets ---")
+		for _, name := range dj.PresetNames() {
+			fmt.Fprintf(out, " 🎛️  %s\n", name)
+		}
+		fmt.Fprintln(out, "---------------")
+		return nil
+	default:
+		return fmt.Errorf("subcomando de preset desconhecido: '%s'", args[0])
+	}
+}
+
+// printNowPlaying writes a one-line summary: the audible instruments and the
+// master tempo.
+func printNowPlaying(dj *DJMixer, out io.Writer) {
+	var playing []string
+	for _, inst := range dj.GetAllInstrumentsSorted() {
+		if inst.GetState() == StatePlaying {
+			playing = append(playing, inst.name)
+		}
+	}
+	names := "nada tocando"
+	if len(playing) > 0 {
+		names = strings.Join(playing, ", ")
+	}
+	fmt.Fprintf(out, "%s %s | %.1f BPM\n", icons().Playing, names, dj.MasterBPM())
 }
 
-func listInstruments(dj *DJMixer) {
-	fmt.Println("--- Instrumentos ---")
+func listInstruments(dj *DJMixer, out io.Writer) {
+	clipDB := dj.ClipThreshold()
+	fmt.Fprintln(out, "--- Instrumentos ---")
+	fmt.Fprintf(out, " %s BPM mestre: %.1f | %s Volume mestre: %+.2f%s%s\n", icons().MasterBPM, dj.MasterBPM(), icons().MasterVol, dj.MasterVolume(), dj.masterStatusSuffix(), clipLED(dj.master.meter, clipDB))
+	if done, total, loading := dj.loading.pending(); loading {
+		fmt.Fprintf(out, " Carregando: %d/%d arquivos\n", done, total)
+	}
 	for _, inst := range dj.GetAllInstrumentsSorted() {
 		state := inst.GetState()
-		icon := "🔇" // Default to muted/stopped icon
-		if state == StatePlaying {
-			icon = "▶️"
-		} else if state == StatePaused {
-			icon = "⏸️"
-		}
-		currentBPM := BaseBPM * inst.speedRatio
-		fmt.Printf(" %s %-10s (Estado: %-7s, Vol: %+.2f, BPM: %.1f)\n", icon, inst.name, state, inst.volume.Volume, currentBPM)
-	}
-	fmt.Println("--------------------")
-}
-
-func printHelp() {
-	fmt.Println("\n--- Comandos da Mesa de DJ ---")
-	fmt.Println("  play [nome]       - Toca ou retoma um instrumento (ou todos).")
-	fmt.Println("  replay [nome]     - Reinicia um instrumento do início (ou todos).")
-	fmt.Println("  pause [nome]      - Pausa um instrumento na posição atual (ou todos).")
-	fmt.Println("  stop [nome]       - Para um instrumento silenciando-o (ou todos).")
-	fmt.Println("  volume <nome> <v> - Define o volume do instrumento (-2.0 a 2.0).")
-	fmt.Println("  bpm <nome> <v>    - Define o BPM do instrumento (ex: 'bpm bateria 140').")
-	fmt.Println("  list             - Mostra o status de todos os instrumentos.")
-	fmt.Println("  help             - Mostra esta mensagem de ajuda.")
-	fmt.Println("  quit             - Sai do programa (ou use Ctrl+C).")
-	fmt.Println("------------------------------")
+		icon := icons().state(state)
+		ratio := inst.SpeedRatio()
+		currentBPM := BaseBPM * ratio
+		repeat := "sim"
+		if count := inst.LoopCount(); count == 1 {
+			repeat = "não"
+		} else if count > 1 {
+			repeat = fmt.Sprintf("%dx", count)
+		}
+		line := fmt.Sprintf(" %s %-10s %s (Estado: %-7s, Vol: %+.2f, BPM: %.1f, Tom: %+.2f st, Repetir: %s)", icon, inst.name, loopBar(inst.LoopProgress()), state, inst.Volume(), currentBPM, semitonesFromRatio(ratio)+inst.Detune()/100, repeat)
+		if fx := inst.Effects().summary(); fx != "" {
+			line += " " + fx
+		}
+		if dj.IsSoloed(inst.name) {
+			line += " [solo]"
+		}
+		if inst.SoloSafe() {
+			line += " [solo-safe]"
+		}
+		if !inst.Enabled() {
+			line += " [desativado]"
+		}
+		if v := inst.Polyphony(); v > 1 {
+			line += fmt.Sprintf(" [poly %d]", v)
+		}
+		line += clipLED(inst.meter, clipDB)
+		fmt.Fprintln(out, line)
+	}
+	for _, alias := range dj.InstrumentAliases() {
+		fmt.Fprintf(out, " %s %-10s (mesmo arquivo que '%s')\n", icons().Alias, alias[0], alias[1])
+	}
+	fmt.Fprintln(out, "--------------------")
+}
+
+// semitonesFromRatio converts a playback speed ratio into the pitch shift it
+// causes, since the resampler changes tempo and pitch together.
+func semitonesFromRatio(ratio float64) float64 {
+	return 12 * math.Log2(ratio)
+}
+
+func printHelp(out io.Writer) {
+	fmt.Fprintln(out, "\n--- Comandos da Mesa de DJ ---")
+	fmt.Fprintln(out, "  play [nome]       - Toca ou retoma um instrumento (ou todos).")
+	fmt.Fprintln(out, "  replay [nome]     - Reinicia um instrumento do início (ou todos).")
+	fmt.Fprintln(out, "  playfrom <nome> <s> - Toca um instrumento a partir de uma posição em segundos.")
+	fmt.Fprintln(out, "  preview <nome> <s> <ms> - Ouve um trecho a partir de uma posição sem mexer no loop.")
+	fmt.Fprintln(out, "  pause [nome]      - Pausa um instrumento na posição atual (ou todos).")
+	fmt.Fprintln(out, "  stop [nome]       - Para um instrumento silenciando-o (ou todos).")
+	fmt.Fprintln(out, "  stop-mode [mute|pause] - mute: parados seguem em fase em silêncio; pause: economiza CPU.")
+	fmt.Fprintln(out, "  playall | pauseall | stopall | replayall - Aplica a ação a todos os instrumentos.")
+	fmt.Fprintln(out, "  exclude|include <nome> - Tira o instrumento dos comandos para todos, ou o devolve.")
+	fmt.Fprintln(out, "  arm [nomes...|off] - Arma instrumentos (ou lista/limpa os armados).")
+	fmt.Fprintln(out, "  go                - Inicia todos os armados exatamente juntos.")
+	fmt.Fprintln(out, "  volume <nome> <v> - Define o volume do instrumento (-2.0 a 2.0).")
+	fmt.Fprintln(out, "  volume all <v|+d> - Define (ou, com +, ajusta: +0.1, +-0.1) o volume de todos.")
+	fmt.Fprintln(out, "  bpm <nome> <v>    - Define o BPM do instrumento (ex: 'bpm bateria 140').")
+	fmt.Fprintln(out, "  trim <nome> <dB>  - Ganho de entrada antes dos efeitos (-24 a 24 dB).")
+	fmt.Fprintln(out, "  flanger <nome> <hz> <prof> <realim> - Aplica flanger (taxa 0 desliga).")
+	fmt.Fprintln(out, "  chorus <nome> <hz> <prof> <vozes>   - Aplica chorus com 1 a 4 vozes (taxa 0 desliga).")
+	fmt.Fprintln(out, "  freeze <nome> on|off - Congela o som atual indefinidamente.")
+	fmt.Fprintln(out, "  drive <nome> <v>  - Aplica distorção (0 = limpo, até 10).")
+	fmt.Fprintln(out, "  lowpass <nome> <hz|off> - Filtro passa-baixa (20 a 20000 Hz).")
+	fmt.Fprintln(out, "  autofilter <nome> <compassos> [prof] [forma] - Varre o passa-baixa no tempo do BPM mestre ('off' desliga).")
+	fmt.Fprintln(out, "  width <nome> <v>  - Largura estéreo (0 = mono, 1 = original, até 2).")
+	fmt.Fprintln(out, "  swaplr <nome> on|off - Inverte os canais esquerdo e direito.")
+	fmt.Fprintln(out, "  phaseinvert <nome> on|off - Inverte a fase (polaridade) do instrumento.")
+	fmt.Fprintln(out, "  chanvol <nome> left|right <v> - Ganho linear de um canal (0 a 2, 1 = original).")
+	fmt.Fprintln(out, "  chanmute <nome> left|right [on|off] - Silencia ou reativa um canal.")
+	fmt.Fprintln(out, "  duck <fonte> <alvos...> <q> [ms] - Sidechain: abaixa os alvos quando a fonte toca.")
+	fmt.Fprintln(out, "  duck <fonte> off  - Remove o ducking acionado pela fonte.")
+	fmt.Fprintln(out, "  masterbpm [v]     - Mostra ou define o BPM mestre (sincroniza todos).")
+	fmt.Fprintln(out, "  tempo-follow [on|off] - O próximo instrumento tocado define o BPM mestre (uma vez).")
+	fmt.Fprintln(out, "  swing [percent]   - Mostra ou define o atraso dos contratempos (0 = reto, 33 = shuffle).")
+	fmt.Fprintln(out, "  xfader [assign <a> <b>|<0-1>|off] - Crossfader entre dois instrumentos (0 = só A, 1 = só B).")
+	fmt.Fprintln(out, "  xfadercurve [smooth|cut] - Curva do crossfader: suave para mixar, corte seco para scratch.")
+	fmt.Fprintln(out, "  beatvis on|off    - Pisca cada batida do BPM mestre na barra de título do terminal.")
+	fmt.Fprintln(out, "  metronome on|off  - Liga ou desliga um metrônomo que segue o BPM mestre e o swing.")
+	fmt.Fprintln(out, "  ramp bpm <v> <s>  - Altera o BPM mestre gradualmente em <s> segundos.")
+	fmt.Fprintln(out, "  offset <nome> <b>  - Adianta o instrumento b batidas em relação à grade (vale também ao reiniciar).")
+	fmt.Fprintln(out, "  jump <nome> <b>   - Salta b batidas do BPM mestre para frente (ou para trás, se negativo) dentro do loop.")
+	fmt.Fprintln(out, "  cue set|jump|clear <nome> <n> - Marca, toca a partir de ou apaga o hot cue n (1-8).")
+	fmt.Fprintln(out, "  pattern <nome> <x.x.x.x.>|off - Toca o instrumento nos x de um compasso, em loop (sem argumentos, lista).")
+	fmt.Fprintln(out, "  roll <nome> <d1> <d2> <c> - Repete um trecho de 1/d1 até 1/d2 de nota ao longo de c compassos (off interrompe).")
+	fmt.Fprintln(out, "  align <nome>      - Move o instrumento para a batida mais próxima da grade do BPM mestre.")
+	fmt.Fprintln(out, "  repeat <nome> on|off - Repete indefinidamente ou toca uma vez e para.")
+	fmt.Fprintln(out, "  reload <nome>     - Relê o arquivo do disco mantendo volume, velocidade e efeitos.")
+	fmt.Fprintln(out, "  detune <nome> <cents> - Ajuste fino de afinação (±100 cents), independente do BPM.")
+	fmt.Fprintln(out, "  poly <nome> <vozes> - Cada 'play' dispara uma nova voz sobreposta (1 desliga).")
+	fmt.Fprintln(out, "  quality <nome> <1-6> - Qualidade do reamostrador (mais alta = menos aliasing, mais CPU).")
+	fmt.Fprintln(out, "  retrigger <nome> on|off - 'play' sempre reinicia do começo (como um sampler).")
+	fmt.Fprintln(out, "  trim-silence <nome> [dB|off] - Tira do loop o silêncio do início e do fim (padrão -60 dB).")
+	fmt.Fprintln(out, "  loop <nome> <n>|inf - Toca o arquivo n vezes e para (ou infinitamente).")
+	fmt.Fprintln(out, "  fade <nome> in|out <s> [curva] - Fade de entrada ou saída (curvas: linear, log, equal).")
+	fmt.Fprintln(out, "  fadecurve [curva] - Mostra ou define a curva padrão dos fades.")
+	fmt.Fprintln(out, "  humanize <nome> <ms> - Varia aleatoriamente cada reinício do loop em até ±ms (0 desliga).")
+	fmt.Fprintln(out, "  testtone <hz> on|off - Liga ou desliga um tom senoidal de teste.")
+	fmt.Fprintln(out, "  noise white|pink on|off - Liga ou desliga um gerador de ruído.")
+	fmt.Fprintln(out, "  limits <nome> [vmin vmax smin smax|reset] - Restringe volume e velocidade do instrumento.")
+	fmt.Fprintln(out, "  reset <nome>|all  - Restaura volume, velocidade, efeitos e posição padrão.")
+	fmt.Fprintln(out, "  undo              - Desfaz a última alteração de volume ou BPM.")
+	fmt.Fprintln(out, "  scene save <n>    - Salva o estado atual da mixagem como uma cena.")
+	fmt.Fprintln(out, "  scene recall <n> [s] - Restaura uma cena (com transição opcional em segundos).")
+	fmt.Fprintln(out, "  scene list        - Lista as cenas salvas.")
+	fmt.Fprintln(out, "  preset save <p> <nome> - Salva os efeitos do instrumento como um preset.")
+	fmt.Fprintln(out, "  preset apply <p> <nome> - Aplica um preset de efeitos ao instrumento.")
+	fmt.Fprintln(out, "  preset list       - Lista os presets salvos.")
+	fmt.Fprintln(out, "  ab store|toggle <nome> - Guarda os efeitos como referência, ou alterna entre ela e as edições.")
+	fmt.Fprintln(out, "  session save|load [arq] - Salva ou restaura a mixagem inteira (padrão: go-dj-session.json).")
+	fmt.Fprintln(out, "  dump              - Mostra os comandos que recriam o estado atual, como um script editável.")
+	fmt.Fprintln(out, "  chokegroup <g> <nomes...>|off - Grupo exclusivo: tocar um para os outros.")
+	fmt.Fprintln(out, "  bind <1-9> <nome>|off - Associa o instrumento a um número: '1 play', '1 vol 0.5' (sem argumentos, lista).")
+	fmt.Fprintln(out, "  link <nomes...>   - Vincula instrumentos: volume e BPM de um valem para todos (sem argumentos, lista).")
+	fmt.Fprintln(out, "  unlink <nome>     - Desfaz o vínculo do instrumento.")
+	fmt.Fprintln(out, "  wait <s>          - Espera s segundos antes do próximo comando (para scripts).")
+	fmt.Fprintln(out, "  sleep [min|cancel] - Desvanece e para tudo após <min> minutos (sem argumentos, mostra o tempo).")
+	fmt.Fprintln(out, "  playlist add <nomes...> - Enfileira instrumentos para tocar um após o outro.")
+	fmt.Fprintln(out, "  playlist start|stop|next|clear - Controla a playlist (sem argumentos, lista).")
+	fmt.Fprintln(out, "  list             - Mostra o status de todos os instrumentos e onde cada um está no loop.")
+	fmt.Fprintln(out, "  np               - Mostra numa linha só o que está tocando e o BPM mestre.")
+	fmt.Fprintln(out, "  reinit-audio     - Reabre o dispositivo de áudio se ele travar, mantendo a mixagem.")
+	fmt.Fprintln(out, "  status <nome>     - Mostra todos os parâmetros de um instrumento.")
+	fmt.Fprintln(out, "  waveform <nome> [n] - Desenha a forma de onda do arquivo em n colunas (padrão 64).")
+	fmt.Fprintln(out, "  history <nome>    - Mostra o nível dos últimos ~2 segundos.")
+	fmt.Fprintln(out, "  mono on|off       - Soma a saída mestre em mono para checar compatibilidade.")
+	fmt.Fprintln(out, "  mastervol [v]     - Mostra ou define o volume mestre (-2.0 a 2.0).")
+	fmt.Fprintln(out, "  clip [dB]         - Mostra ou define o nível (dBFS) que acende o indicador 🔴 CLIP no 'list'.")
+	fmt.Fprintln(out, "  mastereq [g m a|off] - Mostra ou define o EQ mestre em dB (graves, médios, agudos).")
+	fmt.Fprintln(out, "  kill low|mid|high on|off - Corta totalmente uma banda do isolador mestre, ou a devolve.")
+	fmt.Fprintln(out, "  masterreverb <mix> <tamanho>|off - Reverb na saída mestre (0 a 1).")
+	fmt.Fprintln(out, "  masterdelay <ms> <realim> <mix>|off - Delay na saída mestre (até 2000 ms).")
+	fmt.Fprintln(out, "  mastercomp <dB> <razão> [atq rel]|off - Compressor na saída mestre.")
+	fmt.Fprintln(out, "  masterlimit [teto dB]|off - Limitador na saída mestre (padrão -0.3 dB).")
+	fmt.Fprintln(out, "  rack [order <efeitos...>|bypass <efeito> on|off] - Mostra, reordena ou desliga os efeitos mestre.")
+	fmt.Fprintln(out, "  dim on|off        - Atenua a saída mestre para falar por cima da música.")
+	fmt.Fprintln(out, "  mixdown <arq> <s> - Renderiza <s> segundos da mixagem atual em um arquivo WAV.")
+	fmt.Fprintln(out, "  captureloop <c> [nome] - Grava c compassos da saída mestre como um novo instrumento.")
+	fmt.Fprintln(out, "  solo <nome> [on|off] - Ouve só os instrumentos em solo (solo off desfaz todos).")
+	fmt.Fprintln(out, "  solosafe <nome> on|off - Mantém o instrumento audível mesmo com solo ativo.")
+	fmt.Fprintln(out, "  enable|disable <nome> - Reconecta ou desconecta o instrumento da mixagem (economiza CPU).")
+	fmt.Fprintln(out, "  load <nome> <arq|url> - Carrega um arquivo local ou baixado de uma URL http(s).")
+	fmt.Fprintln(out, "  files             - Lista os arquivos do diretório de músicas e quais estão carregados.")
+	fmt.Fprintln(out, "  statusjson        - Mostra o estado de todos os instrumentos em JSON.")
+	fmt.Fprintln(out, "  keys              - Modo de teclas: setas selecionam, espaço toca/pausa, +/- volume, Esc sai.")
+	fmt.Fprintln(out, "  rate              - Mostra a taxa de amostragem da saída e os instrumentos reamostrados.")
+	fmt.Fprintln(out, "  version           - Mostra a versão, o commit e as versões do Go e da beep.")
+	fmt.Fprintln(out, "  help [comando]   - Mostra esta mensagem, ou os detalhes e exemplos de um comando.")
+	fmt.Fprintln(out, "  quit             - Sai do programa (ou use Ctrl+C).")
+	fmt.Fprintln(out, "------------------------------")
 }
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+	"github.com/faiface/beep/speaker"
+)
+
+// DefaultDimDB is how far "dim" drops the master when the config doesn't say.
+const DefaultDimDB = -20.0
+
+// masterBus is the output stage between the instrument mixer and the speaker:
+// mixer → EQ → isolator → mono sum → master volume → effects rack → meter →
+// capture tap.
+// The rack comes after the fader so its limiter also catches a master boost.
+type masterBus struct {
+	eq     *threeBandEQ
+	iso    *isolator
+	mono   *monoSum
+	volume *effects.Volume
+	rack   *effectRack
+	meter  *levelMeter
+	tap    *masterTap
+	out    beep.Streamer
+
+	dimmed    bool
+	dimDB     float64
+	preDimVol float64
+	clipDB    float64
+}
+
+func newMasterBus(input beep.Streamer, sr beep.SampleRate) *masterBus {
+	eq := newThreeBandEQ(input, sr)
+	iso := newIsolator(eq, sr)
+	mono := &monoSum{streamer: iso}
+	volume := &effects.Volume{Streamer: mono, Base: 2}
+	rack := newEffectRack(volume, sr)
+	meter := newLevelMeter(rack, sr)
+	tap := &masterTap{streamer: meter}
+	return &masterBus{eq: eq, iso: iso, mono: mono, volume: volume, rack: rack, meter: meter, tap: tap, out: tap, dimDB: DefaultDimDB, clipDB: DefaultClipDB}
+}
+
+func (m *masterBus) Stream(samples [][2]float64) (n int, ok bool) {
+	return m.out.Stream(samples)
+}
+
+func (m *masterBus) Err() error {
+	return m.out.Err()
+}
+
+// monoSum averages left and right when enabled.
+type monoSum struct {
+	streamer beep.Streamer
+	enabled  bool
+}
+
+func (m *monoSum) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = m.streamer.Stream(samples)
+	if m.enabled {
+		for i := range samples[:n] {
+			mid := (samples[i][0] + samples[i][1]) / 2
+			samples[i][0], samples[i][1] = mid, mid
+		}
+	}
+	return n, ok
+}
+
+func (m *monoSum) Err() error {
+	return m.streamer.Err()
+}
+
+// dbToVolume converts decibels to the base-2 units used by effects.Volume.
+func dbToVolume(db float64) float64 {
+	return db / (20 * math.Log10(2))
+}
+
+// SetMono sums the master output to mono, for checking mono compatibility.
+func (dj *DJMixer) SetMono(mono bool) {
+	speaker.Lock()
+	dj.master.mono.enabled = mono
+	speaker.Unlock()
+	if mono {
+		log.Println("🔈 Saída mestre em mono.")
+	} else {
+		log.Println("🔈 Saída mestre em estéreo.")
+	}
+}
+
+// SetMasterEQ sets the low, mid and high gains of the master EQ in dB, for
+// shaping the whole mix at once.
+func (dj *DJMixer) SetMasterEQ(gains [3]float64) error {
+	for b, db := range gains {
+		if db < MinEQDB || db > MaxEQDB {
+			return errorf(ErrOutOfRange, "ganho de %s %.1f dB está fora do intervalo [%.0f, %.0f]", eqBandNames[b], db, MinEQDB, MaxEQDB)
+		}
+	}
+	speaker.Lock()
+	dj.master.eq.setGains(gains)
+	speaker.Unlock()
+	log.Printf("🎛️  EQ mestre: graves %+.1f dB, médios %+.1f dB, agudos %+.1f dB.", gains[EQLow], gains[EQMid], gains[EQHigh])
+	return nil
+}
+
+// MasterEQ returns the master EQ gains in dB.
+func (dj *DJMixer) MasterEQ() [3]float64 {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return dj.master.eq.gains
+}
+
+// MasterVolume returns the master fader level, ignoring any dim in effect.
+func (dj *DJMixer) MasterVolume() float64 {
+	speaker.Lock()
+	defer speaker.Unlock()
+	if dj.master.dimmed {
+		return dj.master.preDimVol
+	}
+	return dj.master.volume.Volume
+}
+
+func (dj *DJMixer) SetMasterVolume(vol float64) error {
+	if !within(vol, MinVolume, MaxVolume) {
+		return errorf(ErrOutOfRange, "volume mestre %.2f está fora do intervalo permitido [%.2f, %.2f]", vol, MinVolume, MaxVolume)
+	}
+	dj.applyMasterVolume(vol)
+	log.Printf("🔊 Volume mestre definido para %.2f.", vol)
+	return nil
+}
+
+// applyMasterVolume sets the master fader without validation or logging, so
+// fades can call it on every step. A dim in effect stays on top of it.
+func (dj *DJMixer) applyMasterVolume(vol float64) {
+	speaker.Lock()
+	defer speaker.Unlock()
+	m := dj.master
+	if m.dimmed {
+		m.preDimVol = vol
+		m.volume.Volume = vol + dbToVolume(m.dimDB)
+	} else {
+		m.volume.Volume = vol
+	}
+}
+
+// SetDim drops the master by the configured dim amount so the DJ can talk
+// over the music, and restores the previous level when turned off.
+func (dj *DJMixer) SetDim(on bool) error {
+	speaker.Lock()
+	m := dj.master
+	if on == m.dimmed {
+		speaker.Unlock()
+		if on {
+			return errorf(ErrInvalidState, "saída mestre já está atenuada")
+		}
+		return errorf(ErrInvalidState, "saída mestre não está atenuada")
+	}
+	if on {
+		m.preDimVol = m.volume.Volume
+		m.volume.Volume += dbToVolume(m.dimDB)
+	} else {
+		m.volume.Volume = m.preDimVol
+	}
+	m.dimmed = on
+	dimDB := m.dimDB
+	speaker.Unlock()
+	if on {
+		log.Printf("🤫 Dim ligado: saída mestre %.0f dB.", dimDB)
+	} else {
+		log.Println("🔊 Dim desligado: volume mestre restaurado.")
+	}
+	return nil
+}
+
+// masterStatusSuffix flags master modes that change what is heard, for the
+// list header.
+func (dj *DJMixer) masterStatusSuffix() string {
+	speaker.Lock()
+	defer speaker.Unlock()
+	suffix := ""
+	if dj.master.dimmed {
+		suffix += " (dim)"
+	}
+	if dj.master.mono.enabled {
+		suffix += " (mono)"
+	}
+	if eq := dj.master.eq; !eq.flat() {
+		suffix += fmt.Sprintf(" (EQ %+.1f/%+.1f/%+.1f dB)", eq.gains[EQLow], eq.gains[EQMid], eq.gains[EQHigh])
+	}
+	if killed := dj.master.iso.killedNames(); len(killed) > 0 {
+		suffix += " (kill " + strings.Join(killed, ", ") + ")"
+	}
+	if active := dj.master.rack.active(); len(active) > 0 {
+		suffix += " (" + strings.Join(active, " → ") + ")"
+	}
+	return suffix
+}
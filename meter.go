@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+const (
+	levelHistoryWindow = 20 * time.Millisecond // resolution of one history entry
+	levelHistoryLength = 100                   // entries kept, about 2 seconds
+)
+
+// levelMeter is the last stage of an instrument chain and the streamer the
+// mixer plays. It passes audio through untouched and records the peak of the
+// most recent chunk, which other stages (such as sidechain ducking) read from
+// the audio goroutine, plus a short rolling history of window peaks.
+type levelMeter struct {
+	streamer beep.Streamer
+	peak     float64
+	// detached makes the meter drain so the mixer drops the instrument;
+	// dropped records that the mixer has done so.
+	detached bool
+	dropped  bool
+
+	window     int // samples per history entry
+	windowPos  int
+	windowPeak float64
+	// history is a ring of float64 bits written by the audio goroutine and read
+	// by LevelHistory without taking the speaker lock.
+	history [levelHistoryLength]uint64
+	next    uint64
+}
+
+func newLevelMeter(s beep.Streamer, sr beep.SampleRate) *levelMeter {
+	return &levelMeter{streamer: s, window: sr.N(levelHistoryWindow)}
+}
+
+func (m *levelMeter) Stream(samples [][2]float64) (n int, ok bool) {
+	if m.detached {
+		m.dropped = true
+		m.peak = 0
+		return 0, false
+	}
+	n, ok = m.streamer.Stream(samples)
+	peak := 0.0
+	for i := range samples[:n] {
+		p := math.Max(math.Abs(samples[i][0]), math.Abs(samples[i][1]))
+		peak = math.Max(peak, p)
+		m.windowPeak = math.Max(m.windowPeak, p)
+		if m.windowPos++; m.windowPos >= m.window {
+			slot := atomic.LoadUint64(&m.next) % levelHistoryLength
+			atomic.StoreUint64(&m.history[slot], math.Float64bits(m.windowPeak))
+			atomic.AddUint64(&m.next, 1)
+			m.windowPos, m.windowPeak = 0, 0
+		}
+	}
+	m.peak = peak
+	return n, ok
+}
+
+func (m *levelMeter) Err() error {
+	return m.streamer.Err()
+}
+
+// LevelHistory returns the peak level of each recent window, oldest first.
+func (i *Instrument) LevelHistory() []float64 {
+	return i.meter.levels(levelHistoryLength)
+}
+
+// levels returns the peaks of up to count most recent windows, oldest first.
+func (m *levelMeter) levels(count uint64) []float64 {
+	next := atomic.LoadUint64(&m.next)
+	if count > next {
+		count = next
+	}
+	levels := make([]float64, 0, count)
+	for k := next - count; k < next; k++ {
+		levels = append(levels, math.Float64frombits(atomic.LoadUint64(&m.history[k%levelHistoryLength])))
+	}
+	return levels
+}
+
+// recentPeak returns the highest window peak over the last d.
+func (m *levelMeter) recentPeak(d time.Duration) float64 {
+	peak := 0.0
+	for _, v := range m.levels(uint64(d / levelHistoryWindow)) {
+		peak = math.Max(peak, v)
+	}
+	return peak
+}
+
+// sparkline renders levels in [0, 1] as a row of block characters.
+func sparkline(levels []float64) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	var b strings.Builder
+	for _, v := range levels {
+		idx := int(math.Min(v, 1) * float64(len(blocks)-1))
+		b.WriteRune(blocks[idx])
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/wav"
+)
+
+// clone opens a private copy of the instrument with the same parameters and
+// playhead, so it can be rendered without touching live playback.
+func (i *Instrument) clone() (*Instrument, error) {
+	if i.path == "" {
+		return nil, fmt.Errorf("instrumento '%s' não vem de um arquivo", i.name)
+	}
+	c, err := NewInstrument(i.name, i.path)
+	if err != nil {
+		return nil, err
+	}
+	c.rateRatio = i.rateRatio
+	c.detune = i.Detune()
+	if q := i.ResampleQuality(); q != c.quality {
+		c.setResampleQuality(q)
+	}
+	c.Restore(i.Snapshot())
+	i.mu.RLock()
+	c.loop.count = i.loop.count
+	c.loop.start, c.loop.end = i.loop.start, i.loop.end
+	i.mu.RUnlock()
+	pos := c.format.SampleRate.N(i.Position())
+	if err := c.seek(pos); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Mixdown renders duration of everything currently playing into a WAV file at
+// the master sample rate. Each instrument is rendered from a private copy, as
+// fast as the CPU allows, so live playback is unaffected.
+func (dj *DJMixer) Mixdown(path string, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("duração da mixagem deve ser positiva")
+	}
+	var offline beep.Mixer
+	var copies []*Instrument
+	defer func() {
+		for _, c := range copies {
+			c.Close()
+		}
+	}()
+	for _, inst := range dj.GetAllInstrumentsSorted() {
+		if inst.GetState() != StatePlaying {
+			continue
+		}
+		c, err := inst.clone()
+		if err != nil {
+			log.Printf("⚠️  '%s' ficou fora da mixagem: %v", inst.name, err)
+			continue
+		}
+		copies = append(copies, c)
+		offline.Add(c.meter)
+	}
+	if len(copies) == 0 {
+		return fmt.Errorf("nenhum instrumento tocando para mixar")
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("falha ao criar %s: %w", path, err)
+	}
+	defer f.Close()
+	format := beep.Format{SampleRate: dj.sampleRate, NumChannels: 2, Precision: 2}
+	start := time.Now()
+	if err := wav.Encode(f, beep.Take(dj.sampleRate.N(duration), &offline), format); err != nil {
+		return fmt.Errorf("falha ao gravar %s: %w", path, err)
+	}
+	log.Printf("💾 Mixagem de %s com %d instrumento(s) gravada em '%s' (%s).", duration, len(copies), path, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// Preview auditions length of the instrument starting at pos through the main
+// output, using a private copy so the live instrument keeps its playhead and
+// state.
+func (dj *DJMixer) Preview(name string, pos, length time.Duration) error {
+	inst, ok := dj.GetInstrument(name)
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	if length <= 0 {
+		return fmt.Errorf("duração da prévia deve ser positiva")
+	}
+	if pos < 0 || pos >= inst.Length() {
+		return errorf(ErrOutOfRange, "posição %s está fora do intervalo [0s, %s)", pos, inst.Length().Round(time.Millisecond))
+	}
+	c, err := inst.clone()
+	if err != nil {
+		return err
+	}
+	if err := c.seek(c.format.SampleRate.N(pos)); err != nil {
+		c.Close()
+		return fmt.Errorf("falha ao posicionar a prévia: %w", err)
+	}
+	c.applyState(StatePlaying)
+	slice := beep.Seq(
+		beep.Take(dj.sampleRate.N(length), c.meter),
+		beep.Callback(func() { go c.Close() }),
+	)
+	speaker.Lock()
+	dj.mixer.Add(slice)
+	speaker.Unlock()
+	log.Printf("👂 Prévia de '%s' a partir de %s por %s.", name, pos, length)
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// SetOffset staggers the instrument by beats against the grid, so two copies
+// of the same loop can play a call-and-response or a polyrhythm. A playing
+// instrument jumps ahead by the change at once, and every rewind (replay,
+// retrigger, arm) starts from the offset instead of the top. Beats are counted
+// at the file's own tempo, which is the master tempo while it is synced.
+func (i *Instrument) SetOffset(beats float64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	perBeat := float64(i.format.SampleRate) * 60 / BaseBPM
+	start, end := i.loop.region()
+	length := end - start
+	if maxBeats := float64(length) / perBeat; !(beats >= 0 && beats < maxBeats) {
+		return errorf(ErrOutOfRange, "offset %.2f está fora do intervalo [0, %.2f) batidas do loop", beats, maxBeats)
+	}
+	delta := int((beats - i.offset) * perBeat)
+	i.offset = beats
+	if i.state == StatePlaying {
+		speaker.Lock()
+		pos := (i.streamer.Position() - start + delta) % length
+		if pos < 0 {
+			pos += length
+		}
+		err := i.seek(start + pos)
+		speaker.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("↔️  Offset de '%s' definido para %.2f batida(s).", i.name, beats)
+	return nil
+}
+
+// Offset returns the stagger in beats.
+func (i *Instrument) Offset() float64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.offset
+}
+
+// offsetSample is where a rewind starts; the caller must hold i.mu.
+func (i *Instrument) offsetSample() int {
+	return int(i.offset * float64(i.format.SampleRate) * 60 / BaseBPM)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// writePanicLog records a recovered panic with its stack in a timestamped file
+// under --panic-log, for reviewing after a live incident, and returns the
+// file's path.
+func writePanicLog(where string, v any, stack []byte) (string, error) {
+	now := time.Now()
+	path := filepath.Join(*panicLogDir, "go-dj-panic-"+now.Format("20060102-150405.000")+".log")
+	report := fmt.Sprintf("Go DJ %s — pânico em %s\n%s\n\n%v\n\n%s", version, where, now.Format(time.RFC3339), v, stack)
+	if err := os.WriteFile(path, []byte(report), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// reportPanic logs a recovered panic to stderr and to the panic log, and
+// returns an error describing it.
+func reportPanic(where string, v any, stack []byte) error {
+	log.Printf("💥 Pânico em %s: %v\n%s", where, v, stack)
+	path, err := writePanicLog(where, v, stack)
+	if err != nil {
+		log.Printf("⚠️  Falha ao gravar o log de pânico: %v", err)
+		return fmt.Errorf("erro interno em %s: %v", where, v)
+	}
+	return fmt.Errorf("erro interno em %s: %v (detalhes em %s)", where, v, path)
+}
+
+// panicGuard is what the speaker plays. A panic anywhere in the streamer tree
+// is recovered and reported, and that chunk comes out silent, so one bad
+// effect doesn't take the whole program down mid-set. Each distinct panic is
+// written to the panic log once, since a broken stage tends to fail on every
+// chunk.
+type panicGuard struct {
+	streamer beep.Streamer
+	seen     map[string]bool
+}
+
+func (g *panicGuard) Stream(samples [][2]float64) (n int, ok bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			if msg := fmt.Sprint(v); !g.seen[msg] {
+				if g.seen == nil {
+					g.seen = make(map[string]bool)
+				}
+				g.seen[msg] = true
+				// Reporting does file I/O, which the audio goroutine can't wait on.
+				go reportPanic("processamento de áudio", v, debug.Stack())
+			}
+			for i := range samples {
+				samples[i] = [2]float64{}
+			}
+			n, ok = len(samples), true
+		}
+	}()
+	return g.streamer.Stream(samples)
+}
+
+func (g *panicGuard) Err() error {
+	return g.streamer.Err()
+}
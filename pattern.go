@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// MaxPatternSteps is the finest grid a pattern can use in its one bar.
+const MaxPatternSteps = 32
+
+// stepPattern retriggers one instrument on the steps of a one-bar pattern. A
+// new pattern waits in next until the following bar line.
+type stepPattern struct {
+	steps  string
+	next   string
+	cancel context.CancelFunc
+}
+
+// parsePattern checks a pattern such as "x.x.x.x.": x hits, . rests.
+func parsePattern(s string) (string, error) {
+	if len(s) == 0 || len(s) > MaxPatternSteps {
+		return "", errorf(ErrOutOfRange, "padrão deve ter de 1 a %d passos", MaxPatternSteps)
+	}
+	if strings.Trim(s, "x.") != "" {
+		return "", fmt.Errorf("padrão inválido: '%s' (use x para tocar e . para pausa)", s)
+	}
+	return s, nil
+}
+
+// SetPattern plays name on the x steps of steps, which split a bar of the
+// master clock evenly, looping until changed. A running pattern switches at
+// the next bar line.
+func (dj *DJMixer) SetPattern(name, steps string) error {
+	steps, err := parsePattern(steps)
+	if err != nil {
+		return err
+	}
+	inst, ok := dj.GetInstrument(name)
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	if p, running := dj.patterns[inst.name]; running {
+		p.next = steps
+		log.Printf("🥁 Padrão de '%s' muda para %s no próximo compasso.", inst.name, steps)
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &stepPattern{next: steps, cancel: cancel}
+	if dj.patterns == nil {
+		dj.patterns = make(map[string]*stepPattern)
+	}
+	dj.patterns[inst.name] = p
+	go dj.runPattern(ctx, inst, p)
+	log.Printf("🥁 Padrão %s em '%s' a partir do próximo compasso.", steps, inst.name)
+	return nil
+}
+
+// StopPattern stops the pattern on name; the instrument plays out its last hit.
+func (dj *DJMixer) StopPattern(name string) error {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	name = dj.resolveName(name) // SetPattern keys by the instrument's own name
+	p, ok := dj.patterns[name]
+	if !ok {
+		return fmt.Errorf("nenhum padrão ativo em '%s'", name)
+	}
+	p.cancel()
+	delete(dj.patterns, name)
+	log.Printf("🥁 Padrão de '%s' desligado.", name)
+	return nil
+}
+
+// runPattern waits for each step of the master clock and retriggers inst on
+// the hits until ctx is canceled. As in runBeatVisual, every wait is worked
+// out from the clock afresh, so tempo changes are followed without drift.
+func (dj *DJMixer) runPattern(ctx context.Context, inst *Instrument, p *stepPattern) {
+	steps := ""
+	for {
+		// Until the first bar line steps is empty and the only step is the
+		// whole bar.
+		count := max(len(steps), 1)
+		stepBeats := float64(beatsPerBar) / float64(count)
+		beat := dj.clock.Beat()
+		next := (math.Floor(beat/stepBeats) + 1) * stepBeats
+		wait := time.Duration((next - beat) / dj.clock.BPM() * float64(time.Minute))
+		if !sleepCtx(ctx, wait) {
+			return
+		}
+		step := int(math.Round(math.Mod(next, beatsPerBar)/stepBeats)) % count
+		if step == 0 {
+			// A bar line: the place to pick up a new pattern.
+			dj.mu.Lock()
+			p.steps = p.next
+			steps = p.steps
+			dj.mu.Unlock()
+		}
+		if steps[step] == 'x' {
+			if err := inst.hit(); err != nil {
+				log.Printf("⚠️  Padrão de '%s': %v", inst.name, err)
+			}
+		}
+	}
+}
+
+// hit restarts the instrument from the top without logging, for patterns that
+// retrigger it many times a bar.
+func (i *Instrument) hit() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.polyphonic() {
+		i.startVoice()
+		return nil
+	}
+	speaker.Lock()
+	err := i.rewind()
+	if err == nil {
+		i.resetEffectState()
+	}
+	speaker.Unlock()
+	if err != nil {
+		return fmt.Errorf("falha ao reiniciar '%s': %w", i.name, err)
+	}
+	i.volume.Silent = false
+	i.ctrl.Paused = false
+	i.state = StatePlaying
+	return nil
+}
+
+// printPatterns lists the running patterns.
+func (dj *DJMixer) printPatterns(out io.Writer) {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	if len(dj.patterns) == 0 {
+		fmt.Fprintln(out, "Nenhum padrão ativo.")
+		return
+	}
+	names := make([]string, 0, len(dj.patterns))
+	for name := range dj.patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := dj.patterns[name]
+		line := fmt.Sprintf("  %-10s %s", name, p.steps)
+		if p.next != p.steps {
+			line += fmt.Sprintf(" → %s no próximo compasso", p.next)
+		}
+		fmt.Fprintln(out, line)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math"
+)
+
+// phasePreroll is the least number of output samples a phase-locked tempo
+// change renders and throws away, so the new resampler has real audio to
+// interpolate from rather than the silence it starts with.
+const phasePreroll = 64
+
+// heardSource returns the file position, in fractional samples, currently
+// coming out of the resampler: the source position less whatever the resampler
+// has read ahead. wrapped reports that the read-ahead crossed a loop restart.
+// The caller must hold the speaker lock.
+func (i *Instrument) heardSource() (pos float64, wrapped bool) {
+	i.handoff.syncRatio()
+	ahead := float64(i.counter.pulled) - float64(i.handoff.pos)*i.handoff.ratio
+	pos = float64(i.streamer.Position()) - ahead
+	start, end := i.loop.region()
+	if n := float64(end - start); pos < float64(start) && n > 0 {
+		pos, wrapped = float64(start)+math.Mod(pos-float64(start), n)+n, true
+	}
+	return pos, wrapped
+}
+
+// retimeKeepingPhase changes the resample ratio without moving the loop.
+// beep.Resampler.SetRatio rounds its position down, nudging the loop up to a
+// sample back on every change, which adds up over many tempo changes; instead
+// the resampler is rebuilt on the exact sample being heard. The caller must
+// hold i.mu and the speaker lock.
+func (i *Instrument) retimeKeepingPhase(ratio float64) {
+	if i.eos.ended || i.loop.pending > 0 {
+		// Nothing of the file is being heard right now.
+		i.resampler.SetRatio(ratio)
+		return
+	}
+	exact, wrapped := i.heardSource()
+	start, preroll := exact, 0
+	if !i.ctrl.Paused {
+		// A paused source doesn't advance, so it can't be pre-rolled. Otherwise
+		// pick the pre-roll length that lands the restart closest to a whole
+		// sample, since the file can only be sought to one.
+		best := math.Inf(1)
+		for m := phasePreroll; m < 2*phasePreroll; m++ {
+			s := exact - float64(m)*ratio
+			if off := math.Abs(s - math.Round(s)); s >= 0 && off < best {
+				start, preroll, best = s, m, off
+			}
+		}
+	}
+	if err := i.streamer.Seek(int(math.Round(start))); err != nil {
+		i.resampler.SetRatio(ratio)
+		return
+	}
+	if wrapped && i.loop.remaining > 0 {
+		// Back in the pass the loop had already counted as finished.
+		i.loop.remaining++
+	}
+	i.resampler.SetRatio(ratio)
+	i.setResampleQuality(i.quality)
+	if preroll > 0 {
+		n, _ := i.resampler.Stream(make([][2]float64, preroll))
+		i.handoff.pos = n
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// playlist plays instruments one after another, each once, advancing when the
+// current one reaches its end.
+type playlist struct {
+	tracks []string
+	// current is the index of the track playing, or -1 when idle.
+	current int
+	cancel  context.CancelFunc
+	skip    chan struct{}
+}
+
+// PlaylistAdd appends instruments to the end of the playlist.
+func (dj *DJMixer) PlaylistAdd(names []string) error {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	for _, name := range names {
+		if _, ok := dj.instruments[name]; !ok {
+			return instrumentNotFound(name)
+		}
+	}
+	dj.playlist.tracks = append(dj.playlist.tracks, names...)
+	log.Printf("📜 %d faixa(s) adicionada(s) à playlist (%d no total).", len(names), len(dj.playlist.tracks))
+	return nil
+}
+
+// PlaylistClear stops the playlist and empties it.
+func (dj *DJMixer) PlaylistClear() {
+	dj.PlaylistStop()
+	dj.mu.Lock()
+	dj.playlist.tracks = nil
+	dj.mu.Unlock()
+	log.Println("📜 Playlist esvaziada.")
+}
+
+// PlaylistStart plays the playlist from its first track, restarting it if it
+// was already running.
+func (dj *DJMixer) PlaylistStart() error {
+	dj.PlaylistStop()
+	ctx, cancel := context.WithCancel(context.Background())
+	skip := make(chan struct{}, 1)
+	dj.mu.Lock()
+	if len(dj.playlist.tracks) == 0 {
+		dj.mu.Unlock()
+		cancel()
+		return fmt.Errorf("playlist vazia; use 'playlist add <nomes...>' primeiro")
+	}
+	dj.playlist.cancel = cancel
+	dj.playlist.skip = skip
+	dj.mu.Unlock()
+	log.Println("📜 Playlist iniciada.")
+	go dj.runPlaylist(ctx, skip)
+	return nil
+}
+
+// PlaylistStop halts the playlist and stops the track that was playing.
+func (dj *DJMixer) PlaylistStop() {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	if dj.playlist.cancel != nil {
+		dj.playlist.cancel()
+		dj.playlist.cancel = nil
+		dj.playlist.skip = nil
+		log.Println("📜 Playlist interrompida.")
+	}
+}
+
+// PlaylistNext cuts the current track short and moves on to the next one.
+func (dj *DJMixer) PlaylistNext() error {
+	dj.mu.RLock()
+	skip := dj.playlist.skip
+	dj.mu.RUnlock()
+	if skip == nil {
+		return errorf(ErrInvalidState, "a playlist não está tocando")
+	}
+	select {
+	case skip <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// runPlaylist is the playlist controller: it plays each track in turn until the
+// list runs out or ctx is cancelled. While a track plays, the next one is armed
+// to start on the sample right after it, so transitions are gapless.
+func (dj *DJMixer) runPlaylist(ctx context.Context, skip chan struct{}) {
+	// Tracks play once; their own loop counts come back when the playlist ends.
+	counts := make(map[*Instrument]int)
+	defer func() {
+		for inst, count := range counts {
+			inst.mu.Lock()
+			speaker.Lock()
+			inst.loop.count = count
+			speaker.Unlock()
+			inst.mu.Unlock()
+		}
+		dj.mu.Lock()
+		// A restarted playlist already belongs to a newer controller.
+		if dj.playlist.skip == nil || dj.playlist.skip == skip {
+			dj.playlist.current = -1
+		}
+		dj.mu.Unlock()
+	}()
+	oneShot := func(inst *Instrument) {
+		inst.mu.Lock()
+		speaker.Lock()
+		if _, seen := counts[inst]; !seen {
+			counts[inst] = inst.loop.count
+		}
+		inst.loop.count = 1
+		inst.loop.remaining = 1
+		speaker.Unlock()
+		inst.mu.Unlock()
+	}
+
+	var armed *Instrument // already set to start when the previous track ended
+	for idx := 0; ; idx++ {
+		dj.mu.Lock()
+		if ctx.Err() != nil {
+			dj.mu.Unlock()
+			return
+		}
+		if idx >= len(dj.playlist.tracks) {
+			dj.playlist.cancel = nil
+			dj.playlist.skip = nil
+			dj.mu.Unlock()
+			log.Println("📜 Fim da playlist.")
+			return
+		}
+		name := dj.playlist.tracks[idx]
+		dj.playlist.current = idx
+		inst, ok := dj.instruments[name]
+		var next *Instrument
+		if idx+1 < len(dj.playlist.tracks) {
+			next = dj.instruments[dj.playlist.tracks[idx+1]]
+		}
+		dj.mu.Unlock()
+		started := ok && inst == armed
+		armed = nil
+		if !ok {
+			log.Printf("⚠️  Faixa '%s' não está mais carregada; pulando.", name)
+			continue
+		}
+		log.Printf("📜 Faixa %d: %s.", idx+1, name)
+		if !started {
+			oneShot(inst)
+		}
+		if next == inst {
+			next = nil // the same track twice simply replays
+		}
+		if next != nil {
+			oneShot(next)
+		}
+		cont, nextStarted := dj.playTrack(ctx, skip, inst, started, next)
+		if !cont {
+			return
+		}
+		if nextStarted {
+			armed = next
+		}
+	}
+}
+
+// playTrack plays inst once and waits for it to end, with next armed to follow
+// it. started means inst is already playing from a previous handoff. It reports
+// whether the playlist should go on and whether next took over.
+func (dj *DJMixer) playTrack(ctx context.Context, skip <-chan struct{}, inst *Instrument, started bool, next *Instrument) (cont, nextStarted bool) {
+	ended := make(chan struct{}, 1)
+	inst.mu.Lock()
+	inst.endHook = func() {
+		select {
+		case ended <- struct{}{}:
+		default:
+		}
+	}
+	inst.mu.Unlock()
+	defer func() {
+		inst.mu.Lock()
+		inst.endHook = nil
+		inst.mu.Unlock()
+	}()
+
+	if started {
+		inst.applyState(StatePlaying)
+		log.Printf("%s %s começou a tocar.", icons().Playing, inst.name)
+	} else if err := inst.Replay(); err != nil {
+		log.Printf("⚠️  Falha ao tocar '%s': %v", inst.name, err)
+		return true, false
+	}
+	if next != nil {
+		next.mu.Lock()
+		err := next.armAfter(inst)
+		next.mu.Unlock()
+		if err != nil {
+			log.Printf("⚠️  Falha ao preparar '%s': %v", next.name, err)
+			next = nil
+		}
+	}
+	disarm := func() {
+		if next != nil {
+			next.mu.Lock()
+			next.disarm()
+			next.mu.Unlock()
+		}
+	}
+	select {
+	case <-ended:
+		return true, next != nil
+	case <-skip:
+		disarm()
+		_ = inst.Stop()
+		return true, false
+	case <-ctx.Done():
+		disarm()
+		_ = inst.Stop()
+		return false, false
+	}
+}
+
+func printPlaylist(dj *DJMixer, out io.Writer) {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	fmt.Fprintln(out, "--- Playlist ---")
+	for idx, name := range dj.playlist.tracks {
+		marker := "  "
+		if idx == dj.playlist.current {
+			marker = strings.TrimSpace(icons().Playing)
+		}
+		fmt.Fprintf(out, " %s %d. %s\n", marker, idx+1, name)
+	}
+	fmt.Fprintln(out, "----------------")
+}
+
+func handlePlaylistCommand(dj *DJMixer, out io.Writer, args []string) error {
+	if len(args) == 0 {
+		printPlaylist(dj, out)
+		return nil
+	}
+	switch args[0] {
+	case "add":
+		if len(args) < 2 {
+			return fmt.Errorf("uso: playlist add <nomes...>")
+		}
+		return dj.PlaylistAdd(args[1:])
+	case "start":
+		return dj.PlaylistStart()
+	case "stop":
+		dj.PlaylistStop()
+		return nil
+	case "next":
+		return dj.PlaylistNext()
+	case "clear":
+		dj.PlaylistClear()
+		return nil
+	case "list", "ls":
+		printPlaylist(dj, out)
+		return nil
+	default:
+		return fmt.Errorf("subcomando de playlist desconhecido: '%s'", args[0])
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"log"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+	"github.com/faiface/beep/speaker"
+)
+
+// MaxPolyVoices bounds how many overlapping voices one instrument can play.
+const MaxPolyVoices = 16
+
+// voicePool sits right after the transport and, in polyphonic mode, mixes in
+// one-shot voices read from the decoded file held in memory. Each play starts
+// a new voice instead of restarting the file, so rapid retriggers overlap
+// rather than cut each other off; the voices then share the rest of the
+// chain, from speed to fader. They come in after the input trim, so they take
+// its gain here. Finished voices are dropped, and once max are sounding the
+// oldest makes room for the next.
+type voicePool struct {
+	streamer beep.Streamer
+	trim     *effects.Gain
+	buffer   *beep.Buffer // the decoded file; nil when not polyphonic
+	max      int
+	voices   []beep.Streamer
+	tmp      [][2]float64
+}
+
+func (p *voicePool) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = p.streamer.Stream(samples)
+	if len(p.voices) == 0 {
+		return n, ok
+	}
+	for i := range samples[n:] {
+		samples[n+i] = [2]float64{}
+	}
+	if cap(p.tmp) < len(samples) {
+		p.tmp = make([][2]float64, len(samples))
+	}
+	tmp := p.tmp[:len(samples)]
+	gain := 1 + p.trim.Gain
+	live := p.voices[:0]
+	for _, v := range p.voices {
+		vn, vok := v.Stream(tmp)
+		for i := range tmp[:vn] {
+			samples[i][0] += tmp[i][0] * gain
+			samples[i][1] += tmp[i][1] * gain
+		}
+		if vok && vn == len(tmp) {
+			live = append(live, v)
+		}
+	}
+	for i := len(live); i < len(p.voices); i++ {
+		p.voices[i] = nil
+	}
+	p.voices = live
+	return len(samples), true
+}
+
+func (p *voicePool) Err() error {
+	return p.streamer.Err()
+}
+
+// bufferFile decodes a whole file into memory, for voices to read from.
+func bufferFile(path string) (*beep.Buffer, error) {
+	streamer, format, err := decodeFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer streamer.Close()
+	buf := beep.NewBuffer(format)
+	buf.Append(streamer)
+	return buf, nil
+}
+
+// SetPolyphony sets how many voices play can stack up. One turns polyphonic
+// mode off and silences the voices still sounding; more decodes the file into
+// memory so every play can start a fresh voice over the ones still ringing.
+func (i *Instrument) SetPolyphony(voices int) error {
+	if voices < 1 || voices > MaxPolyVoices {
+		return errorf(ErrOutOfRange, "número de vozes %d está fora do intervalo [1, %d]", voices, MaxPolyVoices)
+	}
+	if voices == 1 {
+		i.mu.Lock()
+		speaker.Lock()
+		// Voices play with the file's playhead parked, so an instrument left
+		// playing by trigger would stay silent and refuse play; it stops.
+		if i.polyphonic() && i.state == StatePlaying {
+			i.volume.Silent = true
+			i.state = StateStopped
+		}
+		i.poly.buffer, i.poly.voices, i.poly.max = nil, nil, 0
+		speaker.Unlock()
+		i.mu.Unlock()
+		log.Printf("🎹 %s voltou a ser monofônico.", i.name)
+		return nil
+	}
+	if i.path == "" {
+		return errorf(ErrInvalidState, "instrumento '%s' não vem de um arquivo", i.name)
+	}
+	i.mu.RLock()
+	buf := i.poly.buffer
+	i.mu.RUnlock()
+	if buf == nil {
+		var err error
+		if buf, err = bufferFile(i.path); err != nil {
+			return err
+		}
+	}
+	i.mu.Lock()
+	speaker.Lock()
+	i.poly.buffer, i.poly.max = buf, voices
+	if extra := len(i.poly.voices) - voices; extra > 0 {
+		i.poly.voices = append(i.poly.voices[:0], i.poly.voices[extra:]...)
+	}
+	speaker.Unlock()
+	i.mu.Unlock()
+	log.Printf("🎹 %s polifônico com até %d vozes: cada 'play' dispara uma nova voz.", i.name, voices)
+	return nil
+}
+
+// Polyphony returns the maximum number of voices, 1 when not polyphonic.
+func (i *Instrument) Polyphony() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if i.poly.buffer == nil {
+		return 1
+	}
+	return i.poly.max
+}
+
+// polyphonic reports whether play starts voices; the caller must hold i.mu.
+func (i *Instrument) polyphonic() bool {
+	return i.poly.buffer != nil
+}
+
+// trigger starts a new voice where a rewind of the file would, dropping the
+// oldest one when all are in use. The file's own playhead stays parked. The
+// caller must hold i.mu.
+func (i *Instrument) trigger() {
+	active := i.startVoice()
+	log.Printf("%s %s: nova voz (%d/%d).", icons().Playing, i.name, active, i.poly.max)
+}
+
+// startVoice is trigger without the log line, for patterns, and returns how
+// many voices are sounding. The caller must hold i.mu.
+func (i *Instrument) startVoice() int {
+	speaker.Lock()
+	p := i.poly
+	if len(p.voices) >= p.max {
+		p.voices[0] = nil
+		p.voices = p.voices[1:]
+	}
+	p.voices = append(p.voices, i.voiceSource())
+	active := len(p.voices)
+	i.ctrl.Paused = true
+	speaker.Unlock()
+	i.volume.Silent = false
+	i.state = StatePlaying
+	return active
+}
+
+// voiceSource plays the loop region once, from the offset round to where it
+// started, like one pass of the file. The caller must hold i.mu and the
+// speaker lock.
+func (i *Instrument) voiceSource() beep.Streamer {
+	buf := i.poly.buffer
+	start, end := i.loop.region()
+	end = min(end, buf.Len())
+	from := start + i.offsetSample()
+	if from >= end {
+		from = start
+	}
+	if from == start {
+		return buf.Streamer(start, end)
+	}
+	return beep.Seq(buf.Streamer(from, end), buf.Streamer(start, from))
+}
+
+// silenceVoices cuts every sounding voice; the caller must hold i.mu.
+func (i *Instrument) silenceVoices() {
+	speaker.Lock()
+	i.poly.voices = nil
+	speaker.Unlock()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+)
+
+// DefaultPresetsFile is where effect presets are kept between sessions.
+const DefaultPresetsFile = "go-dj-presets.json"
+
+// loadPresets reads the presets file at path. A missing file yields no
+// presets; a malformed one is an error. Settings left out of a preset keep
+// their neutral value; a preset with a value the effect commands would refuse
+// is skipped with a warning.
+func loadPresets(path string) (map[string]effectSettings, error) {
+	presets := make(map[string]effectSettings)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return presets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler presets %s: %w", path, err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("presets inválidos em %s: %w", path, err)
+	}
+	for name, msg := range raw {
+		e := defaultEffectSettings()
+		if err := json.Unmarshal(msg, &e); err != nil {
+			return nil, fmt.Errorf("preset '%s' inválido em %s: %w", name, path, err)
+		}
+		if err := e.validate(); err != nil {
+			log.Printf("⚠️  Preset '%s' ignorado: %v", name, err)
+			continue
+		}
+		presets[name] = e
+	}
+	return presets, nil
+}
+
+// LoadPresets replaces the mixer's presets with those in path, which later
+// saves also write to.
+func (dj *DJMixer) LoadPresets(path string) error {
+	presets, err := loadPresets(path)
+	if err != nil {
+		return err
+	}
+	dj.mu.Lock()
+	dj.presets, dj.presetsPath = presets, path
+	dj.mu.Unlock()
+	if len(presets) > 0 {
+		log.Printf("🎛️  %d preset(s) de efeitos carregado(s).", len(presets))
+	}
+	return nil
+}
+
+// SavePreset stores the effect chain of instrument instName as preset name and
+// writes every preset to the presets file.
+func (dj *DJMixer) SavePreset(name, instName string) error {
+	inst, ok := dj.GetInstrument(instName)
+	if !ok {
+		return instrumentNotFound(instName)
+	}
+	settings := inst.Effects()
+	dj.mu.Lock()
+	if dj.presets == nil {
+		dj.presets = make(map[string]effectSettings)
+	}
+	prev, existed := dj.presets[name]
+	dj.presets[name] = settings
+	err := dj.writePresets()
+	if err != nil {
+		// Keep memory and disk in agreement.
+		if existed {
+			dj.presets[name] = prev
+		} else {
+			delete(dj.presets, name)
+		}
+	}
+	dj.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	log.Printf("🎛️  Preset '%s' salvo a partir de '%s'.", name, inst.name)
+	return nil
+}
+
+// writePresets saves every preset to the presets file; the caller must hold
+// dj.mu.
+func (dj *DJMixer) writePresets() error {
+	path := dj.presetsPath
+	if path == "" {
+		path = DefaultPresetsFile
+	}
+	data, err := json.MarshalIndent(dj.presets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("falha ao gravar presets em %s: %w", path, err)
+	}
+	return nil
+}
+
+// ApplyPreset sets the effect chain of instrument instName to preset name.
+func (dj *DJMixer) ApplyPreset(name, instName string) error {
+	dj.mu.RLock()
+	settings, ok := dj.presets[name]
+	dj.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("preset '%s' não encontrado", name)
+	}
+	inst, ok := dj.GetInstrument(instName)
+	if !ok {
+		return instrumentNotFound(instName)
+	}
+	prev := inst.Effects()
+	inst.applyEffects(settings)
+	dj.undo.Push(fmt.Sprintf("preset em '%s'", inst.name), func() error {
+		inst.applyEffects(prev)
+		return nil
+	})
+	log.Printf("🎛️  Preset '%s' aplicado em '%s'.", name, inst.name)
+	return nil
+}
+
+// PresetNames returns the saved preset names in alphabetical order.
+func (dj *DJMixer) PresetNames() []string {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	names := make([]string, 0, len(dj.presets))
+	for name := range dj.presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
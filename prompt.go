@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultPrompt is the prompt when the config file doesn't set one.
+const DefaultPrompt = "> "
+
+// promptFields are the placeholders a prompt template can use, each computed
+// from the mixer when the prompt is shown.
+var promptFields = map[string]func(dj *DJMixer) string{
+	"{bpm}": func(dj *DJMixer) string { return fmt.Sprintf("%.0f", dj.MasterBPM()) },
+	"{playing}": func(dj *DJMixer) string {
+		playing := 0
+		for _, inst := range dj.GetAllInstrumentsSorted() {
+			if inst.GetState() == StatePlaying {
+				playing++
+			}
+		}
+		return fmt.Sprint(playing)
+	},
+	"{total}": func(dj *DJMixer) string { return fmt.Sprint(len(dj.GetAllInstrumentsSorted())) },
+	"{vol}":   func(dj *DJMixer) string { return fmt.Sprintf("%+.2f", dj.MasterVolume()) },
+}
+
+// renderPrompt fills in the configured prompt template, e.g.
+// "[{bpm}bpm {playing}▶] > " becomes "[128bpm 3▶] > ".
+func (dj *DJMixer) renderPrompt() string {
+	dj.mu.RLock()
+	prompt := dj.prompt
+	dj.mu.RUnlock()
+	if prompt == "" {
+		return DefaultPrompt
+	}
+	for field, value := range promptFields {
+		if strings.Contains(prompt, field) {
+			prompt = strings.ReplaceAll(prompt, field, value(dj))
+		}
+	}
+	return prompt
+}
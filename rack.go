@@ -0,0 +1,487 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// Parameter ranges of the master rack effects.
+const (
+	MaxReverbMix    = 1.0
+	MaxDelayTime    = 2.0 // seconds
+	MaxDelayFeed    = 0.9
+	MinCompThreshDB = -60.0
+	MaxCompRatio    = 20.0
+	MinLimitDB      = -24.0
+
+	DefaultCompAttack  = 10.0  // ms
+	DefaultCompRelease = 100.0 // ms
+	DefaultLimitDB     = -0.3
+	limiterRelease     = 0.05 // seconds to recover most of the gain after a peak
+)
+
+// rackEffect is a master effect that processes the mix in place.
+type rackEffect interface {
+	process(samples [][2]float64)
+	// describe summarizes the parameters, e.g. "mix 0.30, tamanho 0.80".
+	describe() string
+}
+
+type rackSlot struct {
+	name   string
+	effect rackEffect
+	bypass bool
+}
+
+// effectRack runs the master effects in order; a bypassed slot is skipped and
+// keeps its state. Every effect starts bypassed.
+type effectRack struct {
+	streamer beep.Streamer
+	slots    []*rackSlot
+}
+
+func newEffectRack(s beep.Streamer, sr beep.SampleRate) *effectRack {
+	r := &effectRack{streamer: s}
+	for _, slot := range []*rackSlot{
+		{name: "comp", effect: newCompressor(sr)},
+		{name: "delay", effect: newDelay(sr)},
+		{name: "reverb", effect: newReverb(sr)},
+		{name: "limiter", effect: newLimiter(sr)},
+	} {
+		slot.bypass = true
+		r.slots = append(r.slots, slot)
+	}
+	return r
+}
+
+func (r *effectRack) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = r.streamer.Stream(samples)
+	for _, slot := range r.slots {
+		if !slot.bypass {
+			slot.effect.process(samples[:n])
+		}
+	}
+	return n, ok
+}
+
+func (r *effectRack) Err() error {
+	return r.streamer.Err()
+}
+
+func (r *effectRack) slot(name string) (*rackSlot, error) {
+	for _, slot := range r.slots {
+		if slot.name == name {
+			return slot, nil
+		}
+	}
+	return nil, fmt.Errorf("efeito mestre desconhecido: '%s' (use %s)", name, strings.Join(r.names(), ", "))
+}
+
+func (r *effectRack) names() []string {
+	names := make([]string, len(r.slots))
+	for k, slot := range r.slots {
+		names[k] = slot.name
+	}
+	return names
+}
+
+// active lists the effects in use, in order, for the list header.
+func (r *effectRack) active() []string {
+	var names []string
+	for _, slot := range r.slots {
+		if !slot.bypass {
+			names = append(names, slot.name)
+		}
+	}
+	return names
+}
+
+// --- Reverb ---
+
+// Comb and all-pass delays of the Freeverb reverb at 44.1 kHz; the right
+// channel's are spread a little longer so the tail is wide.
+var (
+	reverbCombs   = []int{1116, 1188, 1277, 1356}
+	reverbAllpass = []int{556, 441}
+)
+
+const (
+	reverbSpread  = 23
+	reverbDamping = 0.2
+	reverbGain    = 0.06 // keeps the summed combs near the dry level
+)
+
+type combFilter struct {
+	buf   []float64
+	pos   int
+	store float64
+}
+
+func (c *combFilter) process(x, feedback float64) float64 {
+	y := c.buf[c.pos]
+	c.store = y*(1-reverbDamping) + c.store*reverbDamping
+	c.buf[c.pos] = x + c.store*feedback
+	c.pos = (c.pos + 1) % len(c.buf)
+	return y
+}
+
+type allpassFilter struct {
+	buf []float64
+	pos int
+}
+
+func (a *allpassFilter) process(x float64) float64 {
+	b := a.buf[a.pos]
+	a.buf[a.pos] = x + b*0.5
+	a.pos = (a.pos + 1) % len(a.buf)
+	return b - x
+}
+
+// reverb is a small Freeverb: parallel damped combs into series all-passes.
+type reverb struct {
+	mix, size float64
+	combs     [2][]combFilter
+	allpass   [2][]allpassFilter
+}
+
+func newReverb(sr beep.SampleRate) *reverb {
+	r := &reverb{mix: 0.25, size: 0.7}
+	scale := float64(sr) / 44100
+	for c := range r.combs {
+		for _, d := range reverbCombs {
+			r.combs[c] = append(r.combs[c], combFilter{buf: make([]float64, int(float64(d+c*reverbSpread)*scale))})
+		}
+		for _, d := range reverbAllpass {
+			r.allpass[c] = append(r.allpass[c], allpassFilter{buf: make([]float64, int(float64(d+c*reverbSpread)*scale))})
+		}
+	}
+	return r
+}
+
+func (r *reverb) process(samples [][2]float64) {
+	feedback := 0.7 + 0.28*r.size
+	for i := range samples {
+		for c := range samples[i] {
+			x := samples[i][c]
+			wet := 0.0
+			for k := range r.combs[c] {
+				wet += r.combs[c][k].process(x*reverbGain, feedback)
+			}
+			for k := range r.allpass[c] {
+				wet = r.allpass[c][k].process(wet)
+			}
+			samples[i][c] = x*(1-r.mix) + wet*r.mix
+		}
+	}
+}
+
+func (r *reverb) describe() string {
+	return fmt.Sprintf("mix %.2f, tamanho %.2f", r.mix, r.size)
+}
+
+// --- Delay ---
+
+type delay struct {
+	sampleRate    beep.SampleRate
+	time          float64 // seconds
+	feedback, mix float64
+	buf           [][2]float64
+	pos           int
+}
+
+func newDelay(sr beep.SampleRate) *delay {
+	return &delay{sampleRate: sr, time: 0.375, feedback: 0.4, mix: 0.3, buf: make([][2]float64, sr.N(time.Duration(MaxDelayTime*float64(time.Second)))+1)}
+}
+
+func (d *delay) process(samples [][2]float64) {
+	lag := max(1, int(d.time*float64(d.sampleRate)))
+	for i := range samples {
+		read := (d.pos - lag + len(d.buf)) % len(d.buf)
+		for c := range samples[i] {
+			x, echo := samples[i][c], d.buf[read][c]
+			d.buf[d.pos][c] = x + echo*d.feedback
+			samples[i][c] = x*(1-d.mix) + echo*d.mix
+		}
+		d.pos = (d.pos + 1) % len(d.buf)
+	}
+}
+
+func (d *delay) describe() string {
+	return fmt.Sprintf("%.0f ms, realimentação %.2f, mix %.2f", d.time*1000, d.feedback, d.mix)
+}
+
+// --- Compressor ---
+
+// compressor is a feed-forward compressor with a peak detector linked across
+// both channels, so the stereo image doesn't shift.
+type compressor struct {
+	sampleRate      beep.SampleRate
+	threshold       float64 // dB
+	ratio           float64
+	attack, release float64 // ms
+	env             float64 // detected level, linear
+}
+
+func newCompressor(sr beep.SampleRate) *compressor {
+	return &compressor{sampleRate: sr, threshold: -18, ratio: 4, attack: DefaultCompAttack, release: DefaultCompRelease}
+}
+
+// smoothing returns the one-pole coefficient that settles in ms milliseconds.
+func smoothing(sr beep.SampleRate, ms float64) float64 {
+	return math.Exp(-1 / (ms / 1000 * float64(sr)))
+}
+
+func (c *compressor) process(samples [][2]float64) {
+	att, rel := smoothing(c.sampleRate, c.attack), smoothing(c.sampleRate, c.release)
+	for i := range samples {
+		peak := math.Max(math.Abs(samples[i][0]), math.Abs(samples[i][1]))
+		coef := rel
+		if peak > c.env {
+			coef = att
+		}
+		c.env = peak + coef*(c.env-peak)
+		over := 20*math.Log10(math.Max(c.env, 1e-9)) - c.threshold
+		if over <= 0 {
+			continue
+		}
+		gain := math.Pow(10, -over*(1-1/c.ratio)/20)
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+	}
+}
+
+func (c *compressor) describe() string {
+	return fmt.Sprintf("limiar %.1f dB, razão %.1f:1, ataque %.0f ms, release %.0f ms", c.threshold, c.ratio, c.attack, c.release)
+}
+
+// --- Limiter ---
+
+// limiter keeps every sample under the ceiling: the gain drops at once on a
+// peak and recovers smoothly. It is meant to sit last, as the mix's safety net.
+type limiter struct {
+	sampleRate beep.SampleRate
+	ceiling    float64 // dB
+	gain       float64
+}
+
+func newLimiter(sr beep.SampleRate) *limiter {
+	return &limiter{sampleRate: sr, ceiling: DefaultLimitDB, gain: 1}
+}
+
+func (l *limiter) process(samples [][2]float64) {
+	ceiling := math.Pow(10, l.ceiling/20)
+	rel := smoothing(l.sampleRate, limiterRelease*1000)
+	for i := range samples {
+		peak := math.Max(math.Abs(samples[i][0]), math.Abs(samples[i][1]))
+		target := 1.0
+		if peak > ceiling {
+			target = ceiling / peak
+		}
+		if target < l.gain {
+			l.gain = target
+		} else {
+			l.gain = target + rel*(l.gain-target)
+		}
+		samples[i][0] *= l.gain
+		samples[i][1] *= l.gain
+	}
+}
+
+func (l *limiter) describe() string {
+	return fmt.Sprintf("teto %.1f dB", l.ceiling)
+}
+
+// --- Control ---
+
+// configureRack sets an effect's parameters through set and switches it on,
+// all under the speaker lock.
+func (dj *DJMixer) configureRack(name string, set func(rackEffect)) error {
+	speaker.Lock()
+	slot, err := dj.master.rack.slot(name)
+	if err != nil {
+		speaker.Unlock()
+		return err
+	}
+	set(slot.effect)
+	slot.bypass = false
+	desc := slot.effect.describe()
+	speaker.Unlock()
+	log.Printf("🎚️  %s mestre ligado: %s.", name, desc)
+	return nil
+}
+
+// SetRackBypass switches a master effect off or back on, keeping its settings.
+func (dj *DJMixer) SetRackBypass(name string, bypass bool) error {
+	speaker.Lock()
+	slot, err := dj.master.rack.slot(name)
+	if err == nil {
+		slot.bypass = bypass
+	}
+	speaker.Unlock()
+	if err != nil {
+		return err
+	}
+	if bypass {
+		log.Printf("🎚️  %s mestre desligado.", name)
+	} else {
+		log.Printf("🎚️  %s mestre ligado.", name)
+	}
+	return nil
+}
+
+// SetRackOrder moves the named effects to the front of the rack in the order
+// given; the rest follow in their current order.
+func (dj *DJMixer) SetRackOrder(names []string) error {
+	speaker.Lock()
+	defer speaker.Unlock()
+	r := dj.master.rack
+	seen := make(map[string]bool)
+	var order []*rackSlot
+	for _, name := range names {
+		slot, err := r.slot(name)
+		if err != nil {
+			return err
+		}
+		if seen[name] {
+			return fmt.Errorf("'%s' aparece mais de uma vez na ordem", name)
+		}
+		seen[name] = true
+		order = append(order, slot)
+	}
+	for _, slot := range r.slots {
+		if !seen[slot.name] {
+			order = append(order, slot)
+		}
+	}
+	r.slots = order
+	log.Printf("🎚️  Rack mestre: %s.", strings.Join(r.names(), " → "))
+	return nil
+}
+
+// printRack lists the master effects in processing order.
+func (dj *DJMixer) printRack(out io.Writer) {
+	speaker.Lock()
+	defer speaker.Unlock()
+	fmt.Fprintln(out, "--- Rack mestre ---")
+	for k, slot := range dj.master.rack.slots {
+		state := "ligado"
+		if slot.bypass {
+			state = "desligado"
+		}
+		fmt.Fprintf(out, " %d. %-8s %-9s %s\n", k+1, slot.name, state, slot.effect.describe())
+	}
+}
+
+// handleRackCommand runs masterreverb, masterdelay, mastercomp, masterlimit
+// and rack.
+func handleRackCommand(dj *DJMixer, out io.Writer, parts []string) error {
+	cmd, args := parts[0], parts[1:]
+	name := map[string]string{"masterreverb": "reverb", "masterdelay": "delay", "mastercomp": "comp", "masterlimit": "limiter"}[cmd]
+	if cmd == "rack" {
+		switch {
+		case len(args) == 0:
+			dj.printRack(out)
+			return nil
+		case args[0] == "order" && len(args) > 1:
+			return dj.SetRackOrder(args[1:])
+		case args[0] == "bypass" && len(args) == 3 && (args[2] == "on" || args[2] == "off"):
+			return dj.SetRackBypass(args[1], args[2] == "on")
+		}
+		return fmt.Errorf("uso: rack | rack order <efeitos...> | rack bypass <efeito> on|off")
+	}
+	if len(args) == 1 && args[0] == "off" {
+		return dj.SetRackBypass(name, true)
+	}
+	switch cmd {
+	case "masterreverb":
+		if len(args) != 2 {
+			return fmt.Errorf("uso: masterreverb <mix 0-1> <tamanho 0-1> | off")
+		}
+		mix, err := parseRange("mix do reverb", args[0], 0, MaxReverbMix)
+		if err != nil {
+			return err
+		}
+		size, err := parseRange("tamanho do reverb", args[1], 0, 1)
+		if err != nil {
+			return err
+		}
+		return dj.configureRack(name, func(e rackEffect) {
+			r := e.(*reverb)
+			r.mix, r.size = mix, size
+		})
+	case "masterdelay":
+		if len(args) != 3 {
+			return fmt.Errorf("uso: masterdelay <ms> <realimentação 0-0.9> <mix 0-1> | off")
+		}
+		ms, err := parseRange("tempo do delay em ms", args[0], 0, MaxDelayTime*1000)
+		if err != nil {
+			return err
+		}
+		if ms == 0 {
+			return errorf(ErrOutOfRange, "tempo do delay deve ser positivo")
+		}
+		feedback, err := parseRange("realimentação do delay", args[1], 0, MaxDelayFeed)
+		if err != nil {
+			return err
+		}
+		mix, err := parseRange("mix do delay", args[2], 0, 1)
+		if err != nil {
+			return err
+		}
+		return dj.configureRack(name, func(e rackEffect) {
+			d := e.(*delay)
+			d.time, d.feedback, d.mix = ms/1000, feedback, mix
+		})
+	case "mastercomp":
+		if len(args) < 2 || len(args) > 4 {
+			return fmt.Errorf("uso: mastercomp <limiar dB> <razão> [ataque ms] [release ms] | off")
+		}
+		threshold, err := parseRange("limiar do compressor em dB", args[0], MinCompThreshDB, 0)
+		if err != nil {
+			return err
+		}
+		ratio, err := parseRange("razão do compressor", args[1], 1, MaxCompRatio)
+		if err != nil {
+			return err
+		}
+		attack, release := DefaultCompAttack, DefaultCompRelease
+		if len(args) > 2 {
+			if attack, err = parseFloatArg("ataque do compressor", args[2]); err != nil {
+				return err
+			}
+		}
+		if len(args) > 3 {
+			if release, err = parseFloatArg("release do compressor", args[3]); err != nil {
+				return err
+			}
+		}
+		if attack <= 0 || release <= 0 {
+			return fmt.Errorf("ataque e release devem ser positivos")
+		}
+		return dj.configureRack(name, func(e rackEffect) {
+			c := e.(*compressor)
+			c.threshold, c.ratio, c.attack, c.release = threshold, ratio, attack, release
+		})
+	default: // masterlimit
+		if len(args) > 1 {
+			return fmt.Errorf("uso: masterlimit [teto dB] | off")
+		}
+		ceiling := DefaultLimitDB
+		if len(args) == 1 {
+			var err error
+			if ceiling, err = parseRange("teto do limitador em dB", args[0], MinLimitDB, 0); err != nil {
+				return err
+			}
+		}
+		return dj.configureRack(name, func(e rackEffect) {
+			e.(*limiter).ceiling = ceiling
+		})
+	}
+}
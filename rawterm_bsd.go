@@ -0,0 +1,30 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// enableRawMode switches stdin to unbuffered, unechoed input and returns a
+// function that restores the previous settings. The BSDs, macOS included,
+// name the termios ioctls TIOCGETA/TIOCSETA where Linux has TCGETS/TCSETS.
+func enableRawMode() (func(), error) {
+	fd := int(os.Stdin.Fd())
+	old, err := unix.IoctlGetTermios(fd, unix.TIOCGETA)
+	if err != nil {
+		return nil, err
+	}
+	raw := *old
+	// ISIG is off too, so Ctrl+C reaches the key loop instead of leaving the
+	// terminal raw behind.
+	raw.Lflag &^= unix.ICANON | unix.ECHO | unix.ISIG
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, unix.TIOCSETA, &raw); err != nil {
+		return nil, err
+	}
+	return func() { _ = unix.IoctlSetTermios(fd, unix.TIOCSETA, old) }, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !windows && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package main
+
+import "fmt"
+
+func enableRawMode() (func(), error) {
+	return nil, fmt.Errorf("modo de teclas não é suportado neste sistema")
+}
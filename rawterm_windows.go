@@ -0,0 +1,29 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminalInput makes the console report arrow keys as the same
+// escape sequences Unix terminals send.
+const enableVirtualTerminalInput = 0x0200
+
+// enableRawMode switches the console to unbuffered, unechoed input and returns
+// a function that restores the previous mode.
+func enableRawMode() (func(), error) {
+	h := windows.Handle(os.Stdin.Fd())
+	var old uint32
+	if err := windows.GetConsoleMode(h, &old); err != nil {
+		return nil, err
+	}
+	raw := old &^ (windows.ENABLE_LINE_INPUT | windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT)
+	raw |= enableVirtualTerminalInput
+	if err := windows.SetConsoleMode(h, raw); err != nil {
+		return nil, err
+	}
+	return func() { _ = windows.SetConsoleMode(h, old) }, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// speakerBuffer is the length of the output buffer.
+const speakerBuffer = time.Second / 10
+
+func initSpeaker(sr beep.SampleRate) error {
+	return speaker.Init(sr, sr.N(speakerBuffer))
+}
+
+// playMaster sends the master bus to the speaker.
+func (dj *DJMixer) playMaster() {
+	speaker.Play(&panicGuard{streamer: dj.master})
+}
+
+// ReinitAudio closes the audio device and opens it again with the same sample
+// rate and buffer, to recover from a stalled or changed device. Only the
+// device is replaced: the mixer and every instrument keep their state and
+// settings, and carry on where they were.
+func (dj *DJMixer) ReinitAudio() error {
+	log.Println("🔌 Reiniciando o áudio...")
+	// Close before Init: Init closes the old device while holding the speaker
+	// lock, which the playback goroutine may be waiting on.
+	speaker.Close()
+	if err := initSpeaker(dj.sampleRate); err != nil {
+		return fmt.Errorf("falha ao reiniciar o áudio em %d Hz: %w", dj.sampleRate, err)
+	}
+	dj.playMaster()
+	log.Printf("🔌 Áudio reiniciado em %d Hz.", dj.sampleRate)
+	return nil
+}
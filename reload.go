@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// Reload re-reads the instrument's file from disk and swaps the new decoding in
+// as the source of the existing chain, so name, volume, speed, effects and
+// transport state all carry over. The playhead keeps its place in time when the
+// new file is long enough and goes back to the start otherwise. If the file no
+// longer decodes, the old source stays in place and the error is returned.
+func (i *Instrument) Reload() error {
+	if i.path == "" {
+		return fmt.Errorf("instrumento '%s' não vem de um arquivo", i.name)
+	}
+	streamer, format, err := decodeFile(i.path)
+	if err != nil {
+		return err
+	}
+	var hash string
+	if i.hash != "" {
+		if hash, err = fileHash(i.path); err != nil {
+			streamer.Close()
+			return err
+		}
+	}
+	// Polyphonic voices read from their own copy of the file, refreshed too.
+	var voiceBuf *beep.Buffer
+	if i.Polyphony() > 1 {
+		if voiceBuf, err = bufferFile(i.path); err != nil {
+			streamer.Close()
+			return err
+		}
+	}
+
+	i.mu.Lock()
+	// The output rate is fixed for the session; recover it from the old file's
+	// rate and the conversion the resampler was doing.
+	outputRate := float64(i.format.SampleRate) / i.rateRatio
+	speaker.Lock()
+	pos := i.format.SampleRate.D(i.streamer.Position())
+	old := i.streamer
+	i.streamer = streamer
+	i.loop.streamer = streamer
+	i.format = format
+	i.rateRatio = float64(format.SampleRate) / outputRate
+	i.resampler.SetRatio(i.resampleRatio())
+	if voiceBuf != nil && i.poly.buffer != nil {
+		i.poly.buffer = voiceBuf
+	}
+	if n := format.SampleRate.N(pos); n < streamer.Len() {
+		err = streamer.Seek(n)
+	}
+	speaker.Unlock()
+	i.waveform = nil
+	i.loadFileCues()
+	if i.silenceDB != 0 {
+		if trimErr := i.trimSilence(i.silenceDB); trimErr != nil {
+			log.Printf("⚠️  Silêncio de '%s' não removido: %v", i.name, trimErr)
+			speaker.Lock()
+			i.loop.start, i.loop.end = 0, 0
+			speaker.Unlock()
+			i.silenceDB = 0
+		}
+	}
+	if hash != "" {
+		i.hash = hash
+	}
+	i.mu.Unlock()
+
+	old.Close()
+	if err != nil {
+		return err
+	}
+	log.Printf("🔄 '%s' recarregado de %s (%s).", i.name, i.path, i.formatSummary())
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// Resampler quality bounds. Higher qualities interpolate over more neighbouring
+// samples, which cuts aliasing at extreme speeds but costs CPU on every
+// instrument, every sample.
+const (
+	DefaultResampleQuality = 4
+	MinResampleQuality     = 1
+	MaxResampleQuality     = 6
+)
+
+func validateResampleQuality(q int) error {
+	if q < MinResampleQuality || q > MaxResampleQuality {
+		return errorf(ErrOutOfRange, "qualidade de reamostragem %d está fora do intervalo [%d, %d]", q, MinResampleQuality, MaxResampleQuality)
+	}
+	return nil
+}
+
+// SetResampleQuality rebuilds the instrument's resampler with quality q. The
+// new resampler starts empty, so whatever the old one had read ahead, a few
+// milliseconds of audio, is skipped.
+func (i *Instrument) SetResampleQuality(q int) error {
+	if err := validateResampleQuality(q); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	speaker.Lock()
+	i.setResampleQuality(q)
+	speaker.Unlock()
+	log.Printf("🎚️  Qualidade de reamostragem de '%s' definida para %d.", i.name, q)
+	return nil
+}
+
+// setResampleQuality swaps in a fresh resampler at the current ratio; the
+// caller must hold the speaker lock if the instrument is in the mixer.
+func (i *Instrument) setResampleQuality(q int) {
+	i.quality = q
+	i.counter.pulled = 0
+	i.resampler = beep.ResampleRatio(q, i.resampler.Ratio(), i.counter)
+	i.handoff.streamer = i.resampler
+	i.handoff.pos, i.handoff.ratio = 0, i.resampler.Ratio()
+}
+
+// ResampleQuality returns the quality of the instrument's resampler.
+func (i *Instrument) ResampleQuality() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.quality
+}
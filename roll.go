@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// Roll bounds. Divisions are note values: 4 rolls quarter notes, 32 rolls
+// thirty-second notes.
+const (
+	MinRollDivision = 1
+	MaxRollDivision = 64
+	MaxRollBars     = 16
+)
+
+// rollArgs names the roll command's numeric arguments, in order, for its
+// errors.
+var rollArgs = [3]string{"divisão inicial do roll", "divisão final do roll", "compassos do roll"}
+
+// rollFade is how many samples each slice fades in and out over, so the
+// repeats don't click.
+const rollFade = 32
+
+// beatRoll repeats a slice of the audio that arrives as the roll starts, the
+// slice getting shorter over the roll, like a snare roll building into a drop.
+// The source keeps playing underneath, so when the roll ends the instrument
+// comes back exactly where it would have been.
+type beatRoll struct {
+	streamer beep.Streamer
+	active   bool
+	buf      [][2]float64 // audio since the roll started, as long as a slice can be
+	elapsed  int          // samples since the roll started
+	total    int          // length of the roll in samples
+	noteLen  float64      // samples in a whole note at the master tempo
+	from, to float64      // divisions at the start and end of the roll
+	slice    int          // where the current slice started, in elapsed samples
+	sliceLen int
+	tmp      [][2]float64
+}
+
+// division returns the note value the roll is at after elapsed samples. It
+// moves geometrically, so each doubling takes the same time.
+func (r *beatRoll) division() float64 {
+	return r.from * math.Pow(r.to/r.from, float64(r.elapsed)/float64(r.total))
+}
+
+func (r *beatRoll) Stream(samples [][2]float64) (n int, ok bool) {
+	if !r.active {
+		return r.streamer.Stream(samples)
+	}
+	if len(r.tmp) < len(samples) {
+		r.tmp = make([][2]float64, len(samples))
+	}
+	live := r.tmp[:len(samples)]
+	n, ok = r.streamer.Stream(live)
+	for k := range live[n:] {
+		live[n+k] = [2]float64{}
+	}
+	for k := range samples {
+		if r.elapsed >= r.total {
+			// Released: the rest of the chunk is the live signal.
+			r.active = false
+			copy(samples[k:], live[k:])
+			return len(samples), true
+		}
+		if r.elapsed < len(r.buf) {
+			r.buf[r.elapsed] = live[k]
+		}
+		offset := r.elapsed - r.slice
+		if offset >= r.sliceLen {
+			r.slice, offset = r.elapsed, 0
+			r.sliceLen = max(1, min(int(r.noteLen/r.division()), len(r.buf)))
+		}
+		gain := 1.0
+		if fade := min(rollFade, r.sliceLen/4); fade > 0 {
+			gain = math.Min(1, math.Min(float64(offset+1), float64(r.sliceLen-offset))/float64(fade))
+		}
+		v := r.buf[offset]
+		samples[k] = [2]float64{v[0] * gain, v[1] * gain}
+		r.elapsed++
+	}
+	return len(samples), true
+}
+
+// cancel drops a roll in progress, so a slice from before a stop or seek
+// doesn't come back on the next play. The caller must hold the speaker lock.
+func (r *beatRoll) cancel() {
+	r.active, r.buf = false, nil
+}
+
+func (r *beatRoll) Err() error {
+	return r.streamer.Err()
+}
+
+// Roll starts a beat roll on an instrument: a slice of what it's playing now
+// repeats at note value from, speeding up to note value to over bars bars of
+// the master tempo, then lets go.
+func (dj *DJMixer) Roll(name string, from, to, bars int) error {
+	if from < MinRollDivision || from > MaxRollDivision || to < MinRollDivision || to > MaxRollDivision {
+		return errorf(ErrOutOfRange, "divisões do roll devem estar no intervalo [%d, %d]", MinRollDivision, MaxRollDivision)
+	}
+	if bars < 1 || bars > MaxRollBars {
+		return errorf(ErrOutOfRange, "duração do roll %d está fora do intervalo [1, %d] compassos", bars, MaxRollBars)
+	}
+	inst, ok := dj.GetInstrument(name)
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	beatLen := float64(dj.sampleRate) * 60 / dj.MasterBPM()
+	noteLen := beatLen * beatsPerBar
+	// Allocated here rather than on the audio thread.
+	buf := make([][2]float64, int(noteLen/float64(min(from, to)))+1)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if inst.state != StatePlaying {
+		return errorf(ErrInvalidState, "'%s' precisa estar tocando para o roll", inst.name)
+	}
+	speaker.Lock()
+	*inst.roll = beatRoll{
+		streamer: inst.roll.streamer,
+		tmp:      inst.roll.tmp,
+		active:   true,
+		buf:      buf,
+		total:    int(beatLen * beatsPerBar * float64(bars)),
+		noteLen:  noteLen,
+		from:     float64(from),
+		to:       float64(to),
+	}
+	speaker.Unlock()
+	log.Printf("🥁 Roll em '%s': 1/%d → 1/%d por %d compasso(s).", inst.name, from, to, bars)
+	return nil
+}
+
+// StopRoll lets go of a roll before it ends.
+func (dj *DJMixer) StopRoll(name string) error {
+	inst, ok := dj.GetInstrument(name)
+	if !ok {
+		return instrumentNotFound(name)
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	speaker.Lock()
+	active := inst.roll.active
+	inst.roll.cancel()
+	speaker.Unlock()
+	if !active {
+		return fmt.Errorf("nenhum roll ativo em '%s'", inst.name)
+	}
+	log.Printf("🥁 Roll em '%s' interrompido.", inst.name)
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// safeVolumeMargin is how close to --max-safe-volume a volume may get before
+// a warning.
+const safeVolumeMargin = 0.5
+
+func validateSafeVolume(vol float64) error {
+	if vol < MinVolume || vol > MaxVolume {
+		return errorf(ErrOutOfRange, "volume seguro %.2f está fora do intervalo [%.2f, %.2f]", vol, MinVolume, MaxVolume)
+	}
+	return nil
+}
+
+// safeVolume caps vol at --max-safe-volume, whatever the instrument's own
+// limits allow, and warns when a volume is capped or close to the cap. Volumes
+// are in base-2 steps, so +2.0 is four times the level, about +12 dB. Without
+// the flag vol is returned as is.
+func safeVolume(name string, vol float64) float64 {
+	limit := *maxSafeVol
+	if limit >= MaxVolume {
+		return vol
+	}
+	if vol > limit {
+		log.Printf("⚠️  Volume de '%s' limitado a %.2f (%s) por --max-safe-volume; %.2f seria alto demais.", name, limit, volumeGain(limit), vol)
+		return limit
+	}
+	if vol > limit-safeVolumeMargin {
+		log.Printf("⚠️  Volume de '%s' em %.2f (%s), perto do limite seguro de %.2f.", name, vol, volumeGain(vol), limit)
+	}
+	return vol
+}
+
+// volumeGain describes a base-2 volume as a gain, e.g. "4.0x, +12 dB".
+func volumeGain(vol float64) string {
+	gain := math.Pow(2, vol)
+	return fmt.Sprintf("%.1fx, %+.0f dB", gain, 20*math.Log10(gain))
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// rampStepInterval is how often parameter transitions are updated.
+const rampStepInterval = 50 * time.Millisecond
+
+// instrumentSnapshot captures the user-adjustable parameters of an instrument.
+type instrumentSnapshot struct {
+	Volume     float64
+	SpeedRatio float64
+	State      InstrumentState
+	Effects    effectSettings
+}
+
+// scene maps instrument names to their saved parameters.
+type scene map[string]instrumentSnapshot
+
+func (i *Instrument) Snapshot() instrumentSnapshot {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return instrumentSnapshot{
+		Volume:     i.volume.Volume,
+		SpeedRatio: i.speedRatio,
+		State:      i.state,
+		Effects:    i.effectSettings(),
+	}
+}
+
+// applyVolume sets the volume without validation or logging; callers pass
+// values that were already validated (snapshots, interpolations). Only
+// --max-safe-volume still applies.
+func (i *Instrument) applyVolume(vol float64) {
+	i.mu.Lock()
+	i.volume.Volume = math.Min(vol, *maxSafeVol)
+	i.mu.Unlock()
+}
+
+// applySpeed sets the speed ratio without validation or logging. Master tempo
+// changes, ramps and scene recalls all come through here, so under
+// --phase-lock it keeps the loop's exact place as SetSpeed does.
+func (i *Instrument) applySpeed(ratio float64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.stopGlide() // ramps and master tempo changes set the speed themselves
+	i.speedRatio = ratio
+	resampleRatio := i.resampleRatio()
+	speaker.Lock()
+	defer speaker.Unlock()
+	if *phaseLock && resampleRatio != i.resampler.Ratio() {
+		i.retimeKeepingPhase(resampleRatio)
+	} else {
+		i.resampler.SetRatio(resampleRatio)
+	}
+}
+
+// applyState moves the instrument to the given transport state.
+func (i *Instrument) applyState(state InstrumentState) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	switch state {
+	case StatePlaying:
+		i.volume.Silent = false
+		i.ctrl.Paused = false
+	case StatePaused:
+		i.volume.Silent = false
+		i.ctrl.Paused = true
+	case StateStopped:
+		i.volume.Silent = true
+		if stopMode() == StopPause {
+			i.ctrl.Paused = true
+		}
+	}
+	i.state = state
+}
+
+// Restore applies a snapshot immediately.
+func (i *Instrument) Restore(s instrumentSnapshot) {
+	i.applyVolume(s.Volume)
+	i.applySpeed(s.SpeedRatio)
+	i.applyState(s.State)
+	i.applyEffects(s.Effects)
+}
+
+// runRamp calls step with the transition progress in (0, 1] every
+// rampStepInterval until duration elapses or ctx is canceled.
+func runRamp(ctx context.Context, duration time.Duration, step func(frac float64)) bool {
+	ticker := time.NewTicker(rampStepInterval)
+	defer ticker.Stop()
+	start := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case now := <-ticker.C:
+			frac := float64(now.Sub(start)) / float64(duration)
+			if frac >= 1 {
+				step(1)
+				return true
+			}
+			step(frac)
+		}
+	}
+}
+
+func lerp(from, to, frac float64) float64 {
+	return from + (to-from)*frac
+}
+
+// SaveScene snapshots every loaded instrument under the given name.
+func (dj *DJMixer) SaveScene(name string) {
+	snap := make(scene)
+	for _, inst := range dj.GetAllInstrumentsSorted() {
+		snap[inst.name] = inst.Snapshot()
+	}
+	dj.mu.Lock()
+	if dj.scenes == nil {
+		dj.scenes = make(map[string]scene)
+	}
+	dj.scenes[name] = snap
+	dj.mu.Unlock()
+	log.Printf("📸 Cena '%s' salva com %d instrumentos.", name, len(snap))
+}
+
+// RecallScene restores a saved scene. With a positive transition, volumes and
+// speeds glide toward the saved values; transport states change immediately.
+// Recalling another scene cancels a transition still in progress.
+func (dj *DJMixer) RecallScene(name string, transition time.Duration) error {
+	dj.mu.Lock()
+	snap, ok := dj.scenes[name]
+	if !ok {
+		dj.mu.Unlock()
+		return fmt.Errorf("cena '%s' não encontrada", name)
+	}
+	if dj.cancelTransition != nil {
+		dj.cancelTransition()
+		dj.cancelTransition = nil
+	}
+	targets := make(map[*Instrument]instrumentSnapshot, len(snap))
+	for instName, s := range snap {
+		if inst, exists := dj.instruments[instName]; exists {
+			targets[inst] = s
+		}
+	}
+	var ctx context.Context
+	if transition > 0 {
+		ctx, dj.cancelTransition = context.WithCancel(context.Background())
+	}
+	dj.mu.Unlock()
+
+	if transition <= 0 {
+		for inst, s := range targets {
+			inst.Restore(s)
+		}
+		log.Printf("🎬 Cena '%s' restaurada.", name)
+		return nil
+	}
+
+	starts := make(map[*Instrument]instrumentSnapshot, len(targets))
+	for inst, s := range targets {
+		starts[inst] = inst.Snapshot()
+		inst.applyState(s.State)
+		inst.applyEffects(s.Effects)
+	}
+	log.Printf("🎬 Transição para a cena '%s' em %s...", name, transition)
+	go func() {
+		done := runRamp(ctx, transition, func(frac float64) {
+			for inst, s := range targets {
+				from := starts[inst]
+				inst.applyVolume(lerp(from.Volume, s.Volume, frac))
+				inst.applySpeed(lerp(from.SpeedRatio, s.SpeedRatio, frac))
+			}
+		})
+		if done {
+			log.Printf("🎬 Cena '%s' restaurada.", name)
+		}
+	}()
+	return nil
+}
+
+// SceneNames returns the saved scene names in alphabetical order.
+func (dj *DJMixer) SceneNames() []string {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	names := make([]string, 0, len(dj.scenes))
+	for name := range dj.scenes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+)
+
+// serveTCP accepts plain-text command connections on addr. Each client sends the
+// same commands accepted on stdin, one per line, and receives the command output
+// followed by "ok" or an "erro: ..." line.
+func serveTCP(dj *DJMixer, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("falha ao escutar em %s: %w", addr, err)
+	}
+	defer ln.Close()
+	log.Printf("🌐 Servidor de comandos TCP escutando em %s.", ln.Addr())
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return fmt.Errorf("falha ao aceitar conexão: %w", err)
+		}
+		go handleTCPConn(dj, conn)
+	}
+}
+
+func handleTCPConn(dj *DJMixer, conn net.Conn) {
+	defer conn.Close()
+	remote := conn.RemoteAddr()
+	log.Printf("🔌 Cliente TCP conectado: %s.", remote)
+	defer log.Printf("🔌 Cliente TCP desconectado: %s.", remote)
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		// Quitting from a remote client only closes its own connection.
+		switch strings.ToLower(line) {
+		case "quit", "exit", "q":
+			fmt.Fprintln(conn, "ok")
+			return
+		}
+		if err := handleCommand(dj, conn, line); errors.Is(err, ErrHeld) {
+			fmt.Fprintln(conn, "held")
+			continue
+		} else if err != nil {
+			fmt.Fprintf(conn, "erro: %v\n", err)
+			continue
+		}
+		fmt.Fprintln(conn, "ok")
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("⚠️  Erro na conexão TCP %s: %v", remote, err)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// DefaultSessionFile is where --autosave writes the session on exit and
+// --resume reads it back.
+const DefaultSessionFile = "go-dj-session.json"
+
+// sessionState is the mix as saved to disk: the master settings and the
+// parameters of every instrument, by name.
+type sessionState struct {
+	MasterBPM    float64                      `json:"masterBpm"`
+	MasterVolume float64                      `json:"masterVolume"`
+	Instruments  map[string]sessionInstrument `json:"instruments"`
+}
+
+type sessionInstrument struct {
+	State      string         `json:"state"`
+	Volume     float64        `json:"volume"`
+	SpeedRatio float64        `json:"speedRatio"`
+	Detune     float64        `json:"detuneCents"`
+	LoopCount  int            `json:"loopCount"`
+	Offset     float64        `json:"offsetBeats"`
+	Effects    effectSettings `json:"effects"`
+}
+
+// UnmarshalJSON starts from a freshly loaded instrument, so what an older or
+// hand-edited file leaves out keeps its neutral value rather than zero: a zero
+// speed ratio would stall the resampler, and zero width and channel gains would
+// silence the instrument.
+func (s *sessionInstrument) UnmarshalJSON(data []byte) error {
+	type plain sessionInstrument
+	p := plain{Volume: DefaultVolume, SpeedRatio: 1, Effects: defaultEffectSettings()}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*s = sessionInstrument(p)
+	return nil
+}
+
+// SaveSession writes the current mix to path.
+func (dj *DJMixer) SaveSession(path string) error {
+	state := sessionState{
+		MasterBPM:    dj.MasterBPM(),
+		MasterVolume: dj.MasterVolume(),
+		Instruments:  make(map[string]sessionInstrument),
+	}
+	for _, inst := range dj.GetAllInstrumentsSorted() {
+		snap := inst.Snapshot()
+		state.Instruments[inst.name] = sessionInstrument{
+			State:      snap.State.String(),
+			Volume:     snap.Volume,
+			SpeedRatio: snap.SpeedRatio,
+			Detune:     inst.Detune(),
+			LoopCount:  inst.LoopCount(),
+			Offset:     inst.Offset(),
+			Effects:    snap.Effects,
+		}
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("falha ao gravar sessão em %s: %w", path, err)
+	}
+	log.Printf("💾 Sessão salva em '%s' (%d instrumentos).", path, len(state.Instruments))
+	return nil
+}
+
+// LoadSession restores a mix saved by SaveSession. Instruments that are no
+// longer loaded are skipped.
+func (dj *DJMixer) LoadSession(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("falha ao ler sessão %s: %w", path, err)
+	}
+	state := sessionState{MasterBPM: BaseBPM}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("sessão inválida em %s: %w", path, err)
+	}
+	if err := dj.SetMasterBPM(state.MasterBPM); err != nil {
+		return err
+	}
+	if err := dj.SetMasterVolume(state.MasterVolume); err != nil {
+		return err
+	}
+	restored := 0
+	for name, s := range state.Instruments {
+		inst, ok := dj.GetInstrument(name)
+		if !ok {
+			log.Printf("⚠️  '%s' da sessão não está carregado; ignorado.", name)
+			continue
+		}
+		if err := inst.restoreSession(s); err != nil {
+			log.Printf("⚠️  '%s' não foi restaurado: %v", name, err)
+			continue
+		}
+		restored++
+	}
+	log.Printf("📂 Sessão de '%s' restaurada (%d instrumentos).", path, restored)
+	return nil
+}
+
+func (i *Instrument) restoreSession(s sessionInstrument) error {
+	var state InstrumentState
+	switch s.State {
+	case StatePlaying.String():
+		state = StatePlaying
+	case StatePaused.String():
+		state = StatePaused
+	case StateStopped.String():
+		state = StateStopped
+	default:
+		return fmt.Errorf("estado desconhecido: '%s'", s.State)
+	}
+	if s.LoopCount == 0 {
+		s.LoopCount = -1
+	}
+	// The file is checked against the commands' bounds before anything
+	// changes, so an instrument is either restored whole or left alone.
+	if err := i.checkSession(s); err != nil {
+		return err
+	}
+	if err := i.SetOffset(s.Offset); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	i.detune = s.Detune
+	i.loop.count = s.LoopCount
+	speaker.Lock()
+	err := i.rewind() // starts from the offset with a full play count
+	speaker.Unlock()
+	i.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	i.Restore(instrumentSnapshot{
+		Volume:     s.Volume,
+		SpeedRatio: s.SpeedRatio,
+		State:      state,
+		Effects:    s.Effects,
+	})
+	return nil
+}
+
+// checkSession refuses the values bpm, volume, detune and the effect commands
+// would refuse, NaN included.
+func (i *Instrument) checkSession(s sessionInstrument) error {
+	i.mu.RLock()
+	limits := i.limits
+	i.mu.RUnlock()
+	if !within(s.SpeedRatio, limits.MinSpeed, limits.MaxSpeed) {
+		return errorf(ErrOutOfRange, "proporção de velocidade %.2f está fora do intervalo [%.2f, %.2f]", s.SpeedRatio, limits.MinSpeed, limits.MaxSpeed)
+	}
+	if !within(s.Volume, limits.MinVolume, limits.MaxVolume) {
+		return errorf(ErrOutOfRange, "volume %.2f está fora do intervalo permitido [%.2f, %.2f]", s.Volume, limits.MinVolume, limits.MaxVolume)
+	}
+	if !within(s.Detune, -MaxDetune, MaxDetune) {
+		return errorf(ErrOutOfRange, "detune %.1f cents está fora do intervalo [%.0f, %.0f]", s.Detune, -MaxDetune, MaxDetune)
+	}
+	return s.Effects.validate()
+}
+
+// autosaveSession writes the session on shutdown. A failure only warns, so it
+// never holds up the exit.
+func (dj *DJMixer) autosaveSession() {
+	if err := dj.SaveSession(DefaultSessionFile); err != nil {
+		log.Printf("⚠️  Sessão não foi salva: %v", err)
+	}
+}
+
+// resumeSession restores the session saved on the last exit, if any.
+func (dj *DJMixer) resumeSession() {
+	if _, err := os.Stat(DefaultSessionFile); err != nil {
+		log.Printf("ℹ️  Nenhuma sessão salva em '%s' para retomar.", DefaultSessionFile)
+		return
+	}
+	if err := dj.LoadSession(DefaultSessionFile); err != nil {
+		log.Printf("⚠️  Sessão não foi retomada: %v", err)
+	}
+}
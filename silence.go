@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/wav"
+)
+
+// DefaultSilenceDB is the level below which leading and trailing audio counts
+// as silence when trimming.
+const DefaultSilenceDB = -60.0
+
+// Accepted range for the silence threshold, in dBFS.
+const (
+	MinSilenceDB = -120.0
+	MaxSilenceDB = -6.0
+)
+
+// findAudibleRegion decodes path on its own reader and returns the samples
+// between the first and the last one louder than thresholdDB, end exclusive.
+func findAudibleRegion(path string, thresholdDB float64) (start, end int, err error) {
+	if path == "" {
+		return 0, 0, fmt.Errorf("instrumento não vem de um arquivo")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("falha ao abrir arquivo %s: %w", path, err)
+	}
+	defer f.Close()
+	streamer, _, err := wav.Decode(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("falha ao decodificar arquivo WAV %s: %w", path, err)
+	}
+	threshold := math.Pow(10, thresholdDB/20)
+	start = -1
+	buf := make([][2]float64, 4096)
+	pos := 0
+	for {
+		sn, ok := streamer.Stream(buf)
+		for k, s := range buf[:sn] {
+			if math.Max(math.Abs(s[0]), math.Abs(s[1])) > threshold {
+				if start < 0 {
+					start = pos + k
+				}
+				end = pos + k + 1
+			}
+		}
+		pos += sn
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return 0, 0, fmt.Errorf("falha ao ler %s: %w", path, err)
+	}
+	if start < 0 {
+		return 0, 0, fmt.Errorf("arquivo %s é todo silêncio abaixo de %.0f dB", path, thresholdDB)
+	}
+	return start, end, nil
+}
+
+// TrimSilence finds the near-silent stretches at the start and end of the file
+// and sets the loop to play only what lies between them, so padding in the file
+// doesn't leave gaps at each restart.
+func (i *Instrument) TrimSilence(thresholdDB float64) error {
+	if thresholdDB < MinSilenceDB || thresholdDB > MaxSilenceDB {
+		return errorf(ErrOutOfRange, "limiar de silêncio %.0f dB está fora do intervalo [%.0f, %.0f]", thresholdDB, MinSilenceDB, MaxSilenceDB)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err := i.trimSilence(thresholdDB); err != nil {
+		return err
+	}
+	start, end := i.loop.region()
+	length := i.streamer.Len()
+	sr := i.format.SampleRate
+	log.Printf("✂️  Silêncio de '%s' removido do loop: %s no início, %s no fim.", i.name, sr.D(start).Round(time.Millisecond), sr.D(length-end).Round(time.Millisecond))
+	return nil
+}
+
+// trimSilence bounds the loop to the audible part of the file and remembers
+// the threshold, so a reload trims the new file too; the caller must hold i.mu.
+func (i *Instrument) trimSilence(thresholdDB float64) error {
+	start, end, err := findAudibleRegion(i.path, thresholdDB)
+	if err != nil {
+		return err
+	}
+	speaker.Lock()
+	err = i.setLoopRegion(start, min(end, i.streamer.Len()))
+	speaker.Unlock()
+	if err != nil {
+		return err
+	}
+	i.silenceDB = thresholdDB
+	return nil
+}
+
+// ClearLoopRegion makes the loop cover the whole file again.
+func (i *Instrument) ClearLoopRegion() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.silenceDB = 0
+	speaker.Lock()
+	err := i.setLoopRegion(0, 0)
+	speaker.Unlock()
+	if err != nil {
+		return err
+	}
+	log.Printf("✂️  Loop de '%s' volta a cobrir o arquivo inteiro.", i.name)
+	return nil
+}
+
+// setLoopRegion bounds the loop to [start, end) of the file, end 0 being the
+// end of the file. A playhead before the region moves to its start. The caller
+// must hold i.mu and the speaker lock.
+func (i *Instrument) setLoopRegion(start, end int) error {
+	i.loop.start, i.loop.end = start, end
+	if i.streamer.Position() < start {
+		return i.seek(start)
+	}
+	return nil
+}
+
+// LoopRegion returns the bounds of the loop when silence was trimmed from it.
+func (i *Instrument) LoopRegion() (start, end time.Duration, ok bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if i.silenceDB == 0 {
+		return 0, 0, false
+	}
+	s, e := i.loop.region()
+	return i.format.SampleRate.D(s), i.format.SampleRate.D(e), true
+}
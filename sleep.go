@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// SleepFadeDuration is how long the sleep timer takes to fade the master out.
+	SleepFadeDuration = 10 * time.Second
+	// sleepFadeDepth is how far the fade goes, in volume units (about -60 dB).
+	sleepFadeDepth = 10.0
+)
+
+// Sleep schedules a fade-out of the master followed by stopping every
+// instrument after d. A new call replaces the previous timer.
+func (dj *DJMixer) Sleep(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("tempo do timer deve ser positivo")
+	}
+	dj.CancelSleep()
+	ctx, cancel := context.WithCancel(context.Background())
+	dj.mu.Lock()
+	dj.sleepAt = time.Now().Add(d)
+	dj.cancelSleep = cancel
+	dj.sleepTimer = time.AfterFunc(d, func() { dj.fallAsleep(ctx) })
+	dj.mu.Unlock()
+	log.Printf("😴 Timer: a música vai sumir em %s.", d.Round(time.Second))
+	return nil
+}
+
+// CancelSleep stops a pending timer or a sleep fade in progress, restoring the
+// master volume. It reports whether there was anything to cancel.
+func (dj *DJMixer) CancelSleep() bool {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	if dj.cancelSleep == nil {
+		return false
+	}
+	dj.sleepTimer.Stop()
+	dj.cancelSleep()
+	dj.cancelSleep = nil
+	dj.sleepTimer = nil
+	return true
+}
+
+// SleepRemaining returns the time left before the sleep fade starts.
+func (dj *DJMixer) SleepRemaining() (time.Duration, bool) {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	if dj.cancelSleep == nil {
+		return 0, false
+	}
+	remaining := time.Until(dj.sleepAt)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// fallAsleep fades the master out, stops everything and puts the master fader
+// back where it was, so the next play is heard at the usual level.
+func (dj *DJMixer) fallAsleep(ctx context.Context) {
+	from := dj.MasterVolume()
+	log.Printf("😴 Timer encerrado: desvanecendo em %s...", SleepFadeDuration)
+	done := runRamp(ctx, SleepFadeDuration, func(frac float64) {
+		dj.applyMasterVolume(from - sleepFadeDepth*frac)
+	})
+	if done {
+		dj.ForEachInstrument(func(i *Instrument) error { return i.Stop() })
+		log.Println("😴 Boa noite: todos os instrumentos parados.")
+	}
+	dj.applyMasterVolume(from)
+	dj.mu.Lock()
+	if ctx.Err() == nil {
+		dj.cancelSleep = nil
+		dj.sleepTimer = nil
+	}
+	dj.mu.Unlock()
+}
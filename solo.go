@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// soloCut silences an instrument that solo is keeping out of the mix, without
+// touching its transport or fader.
+type soloCut struct {
+	streamer beep.Streamer
+	cut      bool
+}
+
+func (c *soloCut) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = c.streamer.Stream(samples)
+	if c.cut {
+		for i := range samples[:n] {
+			samples[i] = [2]float64{}
+		}
+	}
+	return n, ok
+}
+
+func (c *soloCut) Err() error {
+	return c.streamer.Err()
+}
+
+// Solo adds an instrument to, or removes it from, the soloed set. While any
+// instrument is soloed, only soloed and solo-safe instruments are heard.
+func (dj *DJMixer) Solo(name string, on bool) error {
+	dj.mu.Lock()
+	name = dj.resolveName(name)
+	if _, ok := dj.instruments[name]; !ok {
+		dj.mu.Unlock()
+		return instrumentNotFound(name)
+	}
+	if on {
+		if dj.soloed == nil {
+			dj.soloed = make(map[string]bool)
+		}
+		dj.soloed[name] = true
+	} else {
+		delete(dj.soloed, name)
+	}
+	dj.mu.Unlock()
+	dj.applySolo()
+	if on {
+		log.Printf("🎧 Solo em '%s'.", name)
+	} else {
+		log.Printf("🎧 Solo de '%s' desfeito.", name)
+	}
+	return nil
+}
+
+// ClearSolo unsolos everything, so all instruments are heard again.
+func (dj *DJMixer) ClearSolo() {
+	dj.mu.Lock()
+	dj.soloed = nil
+	dj.mu.Unlock()
+	dj.applySolo()
+	log.Println("🎧 Nenhum instrumento em solo.")
+}
+
+// SetSoloSafe keeps the instrument audible whatever is soloed, for a click or a
+// reference loop.
+func (i *Instrument) SetSoloSafe(safe bool) {
+	i.mu.Lock()
+	i.soloSafe = safe
+	i.mu.Unlock()
+	if safe {
+		log.Printf("🛟 %s é solo-safe: nunca é silenciado pelo solo.", i.name)
+	} else {
+		log.Printf("🛟 %s deixou de ser solo-safe.", i.name)
+	}
+}
+
+// SoloSafe reports whether solo leaves the instrument alone.
+func (i *Instrument) SoloSafe() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.soloSafe
+}
+
+// IsSoloed reports whether the named instrument is soloed.
+func (dj *DJMixer) IsSoloed(name string) bool {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	return dj.soloed[name]
+}
+
+// applySolo recomputes which instruments solo silences.
+func (dj *DJMixer) applySolo() {
+	dj.mu.RLock()
+	soloing := len(dj.soloed) > 0
+	cuts := make(map[*Instrument]bool, len(dj.instruments))
+	for name, inst := range dj.instruments {
+		cuts[inst] = soloing && !dj.soloed[name]
+	}
+	dj.mu.RUnlock()
+	for inst, cut := range cuts {
+		inst.mu.RLock()
+		speaker.Lock()
+		inst.solo.cut = cut && !inst.soloSafe
+		speaker.Unlock()
+		inst.mu.RUnlock()
+	}
+}
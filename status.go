@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// instrumentStatus is the machine-readable view of an instrument.
+type instrumentStatus struct {
+	Name       string          `json:"name"`
+	State      string          `json:"state"`
+	Volume     float64         `json:"volume"`
+	BPM        float64         `json:"bpm"`
+	Position   float64         `json:"position"`   // seconds
+	Length     float64         `json:"length"`     // seconds
+	SampleRate int             `json:"sampleRate"` // of the file, as decoded
+	Channels   int             `json:"channels"`
+	BitDepth   int             `json:"bitDepth"`
+	Effects    effectSettings  `json:"effects"`
+	Cues       map[int]float64 `json:"cues,omitempty"` // slot → seconds
+	Hash       string          `json:"hash,omitempty"`
+}
+
+// Position returns the playhead within the file.
+func (i *Instrument) Position() time.Duration {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return i.format.SampleRate.D(i.streamer.Position())
+}
+
+// LoopProgress returns how far the playhead is through the loop, from 0 to 1.
+func (i *Instrument) LoopProgress() float64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	speaker.Lock()
+	defer speaker.Unlock()
+	start, end := i.loop.region()
+	if end <= start {
+		return 0
+	}
+	pos := (i.streamer.Position() - start) % (end - start)
+	if pos < 0 {
+		pos += end - start
+	}
+	return float64(pos) / float64(end-start)
+}
+
+// loopBarWidth is how many cells the loop position bar in list has.
+const loopBarWidth = 10
+
+// loopBar draws a position within the loop as a bar, e.g. "[===.......]".
+func loopBar(progress float64) string {
+	filled := min(int(progress*loopBarWidth+0.5), loopBarWidth)
+	return "[" + strings.Repeat("=", filled) + strings.Repeat(".", loopBarWidth-filled) + "]"
+}
+
+// Length returns the duration of the file.
+func (i *Instrument) Length() time.Duration {
+	return i.format.SampleRate.D(i.streamer.Len())
+}
+
+func (i *Instrument) Status() instrumentStatus {
+	snap := i.Snapshot()
+	return instrumentStatus{
+		Name:       i.name,
+		State:      snap.State.String(),
+		Volume:     snap.Volume,
+		BPM:        BaseBPM * snap.SpeedRatio,
+		Position:   i.Position().Seconds(),
+		Length:     i.Length().Seconds(),
+		SampleRate: int(i.format.SampleRate),
+		Channels:   i.format.NumChannels,
+		BitDepth:   i.format.Precision * 8,
+		Effects:    snap.Effects,
+		Cues:       cueSeconds(i.Cues()),
+		Hash:       i.hash,
+	}
+}
+
+// StatusJSON returns the state of every instrument as a JSON array, sorted by name.
+func (dj *DJMixer) StatusJSON() ([]byte, error) {
+	instruments := dj.GetAllInstrumentsSorted()
+	statuses := make([]instrumentStatus, 0, len(instruments))
+	for _, inst := range instruments {
+		statuses = append(statuses, inst.Status())
+	}
+	return json.Marshal(statuses)
+}
+
+// printInstrumentStatus writes every parameter of a single instrument.
+func printInstrumentStatus(out io.Writer, inst *Instrument) {
+	st := inst.Status()
+	fx := st.Effects
+	fmt.Fprintf(out, "--- %s ---\n", st.Name)
+	fmt.Fprintf(out, "  Estado:   %s\n", st.State)
+	fmt.Fprintf(out, "  Trim:     %+.1f dB\n", fx.Trim)
+	fmt.Fprintf(out, "  Volume:   %+.2f\n", st.Volume)
+	fmt.Fprintf(out, "  BPM:      %.1f\n", st.BPM)
+	fmt.Fprintf(out, "  Posição:  %.2fs / %.2fs\n", st.Position, st.Length)
+	fmt.Fprintf(out, "  Formato:  %s\n", inst.formatSummary())
+	fmt.Fprintf(out, "  Flanger:  %.2f Hz, profundidade %.2f, realimentação %.2f\n", fx.Flanger.RateHz, fx.Flanger.Depth, fx.Flanger.Feedback)
+	fmt.Fprintf(out, "  Chorus:   %.2f Hz, profundidade %.2f, %d vozes\n", fx.Chorus.RateHz, fx.Chorus.Depth, fx.Chorus.Voices)
+	fmt.Fprintf(out, "  Drive:    %.1f\n", fx.Drive)
+	if fx.LowPass > 0 {
+		fmt.Fprintf(out, "  Filtro:   passa-baixa em %.0f Hz\n", fx.LowPass)
+	}
+	if p, ok := inst.AutoFilterActive(); ok {
+		fmt.Fprintf(out, "  Autofilter: %d compasso(s), profundidade %.2f, %s\n", p.Bars, p.Depth, p.Shape)
+	}
+	fmt.Fprintf(out, "  Inverter: %s\n", onOff(fx.SwapLR))
+	if fx.PhaseInvert {
+		fmt.Fprintln(out, "  Fase:     invertida")
+	} else {
+		fmt.Fprintln(out, "  Fase:     normal")
+	}
+	fmt.Fprintf(out, "  Largura:  %.2f\n", fx.Width)
+	fmt.Fprintf(out, "  Canais:   L %.2f%s, R %.2f%s\n", fx.ChannelGain[0], mutedSuffix(fx.ChannelMute[0]), fx.ChannelGain[1], mutedSuffix(fx.ChannelMute[1]))
+	if start, end, ok := inst.LoopRegion(); ok {
+		fmt.Fprintf(out, "  Loop:     %.2fs a %.2fs (silêncio removido)\n", start.Seconds(), end.Seconds())
+	}
+	if o := inst.Offset(); o != 0 {
+		fmt.Fprintf(out, "  Offset:   %.2f batida(s)\n", o)
+	}
+	if d := inst.Detune(); d != 0 {
+		fmt.Fprintf(out, "  Detune:   %+.1f cents\n", d)
+	}
+	fmt.Fprintf(out, "  Retrigger: %s\n", onOff(inst.Retrigger()))
+	if inst.ExcludedFromAll() {
+		fmt.Fprintln(out, "  Fora dos comandos para todos")
+	}
+	if h := inst.Humanize(); h > 0 {
+		fmt.Fprintf(out, "  Humanize: ±%s\n", h)
+	}
+	if cues := inst.Cues(); len(cues) > 0 {
+		fmt.Fprintf(out, "  Cues:     %s\n", cueSummary(cues))
+	}
+	if st.Hash != "" {
+		fmt.Fprintf(out, "  SHA-256:  %s\n", st.Hash)
+	}
+}
+
+// formatSummary describes the decoded file: sample rate, channels, bit depth
+// and duration.
+func (i *Instrument) formatSummary() string {
+	channels := fmt.Sprintf("%d canais", i.format.NumChannels)
+	switch i.format.NumChannels {
+	case 1:
+		channels = "mono"
+	case 2:
+		channels = "estéreo"
+	}
+	return fmt.Sprintf("%d Hz, %s, %d bits, %s", i.format.SampleRate, channels, i.format.Precision*8, i.Length().Round(time.Millisecond))
+}
+
+func mutedSuffix(muted bool) string {
+	if muted {
+		return " (mudo)"
+	}
+	return ""
+}
+
+func onOff(b bool) string {
+	if b {
+		return "sim"
+	}
+	return "não"
+}
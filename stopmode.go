@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// StopMode is what Stop does to an instrument's source.
+type StopMode int32
+
+const (
+	// StopMute silences the instrument but keeps its source running, so it
+	// stays in phase with everything else and play brings it back in time.
+	StopMute StopMode = iota
+	// StopPause also pauses the source, which stops reading the file but
+	// leaves the instrument where it was stopped, out of phase with the rest.
+	StopPause
+)
+
+var stopModeNames = []string{"mute", "pause"}
+
+func (m StopMode) String() string {
+	return stopModeNames[m]
+}
+
+func parseStopMode(s string) (StopMode, error) {
+	for k, name := range stopModeNames {
+		if s == name {
+			return StopMode(k), nil
+		}
+	}
+	return 0, fmt.Errorf("modo de parada desconhecido: '%s' (use %s)", s, strings.Join(stopModeNames, " ou "))
+}
+
+// currentStopMode is set by --stop-mode and the stop-mode command.
+var currentStopMode atomic.Int32
+
+func stopMode() StopMode {
+	return StopMode(currentStopMode.Load())
+}
+
+func setStopMode(m StopMode) {
+	currentStopMode.Store(int32(m))
+}
+
+// SetStopMode changes what stop does from now on; instruments already stopped
+// keep the mode they were stopped with until played again.
+func (dj *DJMixer) SetStopMode(m StopMode) {
+	setStopMode(m)
+	switch m {
+	case StopMute:
+		log.Println("⏹️  stop silencia e mantém os instrumentos correndo em fase.")
+	case StopPause:
+		log.Println("⏹️  stop silencia e pausa os instrumentos, economizando CPU (a fase se perde).")
+	}
+}
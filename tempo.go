@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// MaxSwing caps swing so a delayed off-beat never reaches the next beat.
+const MaxSwing = 75.0
+
+// beatClock tracks the musical position of the master tempo. Every tempo change
+// re-anchors the clock so beats counted so far are preserved, keeping the grid
+// continuous while the BPM moves.
+type beatClock struct {
+	mu         sync.Mutex
+	bpm        float64
+	anchor     time.Time
+	anchorBeat float64
+	swing      float64 // percent of an eighth note that off-beats are delayed
+}
+
+func newBeatClock(bpm float64) *beatClock {
+	return &beatClock{bpm: bpm, anchor: time.Now()}
+}
+
+func (c *beatClock) beatAt(t time.Time) float64 {
+	return c.anchorBeat + t.Sub(c.anchor).Minutes()*c.bpm
+}
+
+// Beat returns the current (fractional) beat count since the clock started.
+func (c *beatClock) Beat() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.beatAt(time.Now())
+}
+
+func (c *beatClock) BPM() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bpm
+}
+
+func (c *beatClock) SetBPM(bpm float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.anchorBeat = c.beatAt(now)
+	c.anchor = now
+	c.bpm = bpm
+}
+
+func (c *beatClock) Swing() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.swing
+}
+
+func (c *beatClock) SetSwing(percent float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.swing = percent
+}
+
+// offbeat returns where the off-beat eighth falls within a beat: halfway when
+// straight, later as swing grows.
+func (c *beatClock) offbeat() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return 0.5 + 0.5*c.swing/100
+}
+
+// subdivisionBeat returns the beat on which eighth note n lands. Even
+// subdivisions sit on the beat; odd ones are pushed back by the swing.
+func subdivisionBeat(n int, offbeat float64) float64 {
+	beat := float64(n / 2)
+	if n%2 == 1 {
+		beat += offbeat
+	}
+	return beat
+}
+
+// MasterBPM returns the current master tempo.
+func (dj *DJMixer) MasterBPM() float64 {
+	return dj.clock.BPM()
+}
+
+// SetMasterBPM changes the master tempo and syncs every instrument to it.
+func (dj *DJMixer) SetMasterBPM(bpm float64) error {
+	// within fails NaN too: it would stall the beat clock and make the
+	// resampler index out of range.
+	ratio := bpm / BaseBPM
+	if !within(ratio, MinSpeedRatio, MaxSpeedRatio) {
+		return errorf(ErrOutOfRange, "BPM mestre %.1f está fora do intervalo [%.1f, %.1f]", bpm, BaseBPM*MinSpeedRatio, BaseBPM*MaxSpeedRatio)
+	}
+	dj.setMasterBPM(bpm)
+	log.Printf("🥁 BPM mestre definido para %.1f.", bpm)
+	return nil
+}
+
+// setMasterBPM applies an already validated tempo without logging, so ramps
+// can call it on every step.
+func (dj *DJMixer) setMasterBPM(bpm float64) {
+	dj.clock.SetBPM(bpm)
+	ratio := bpm / BaseBPM
+	for _, inst := range dj.GetAllInstrumentsSorted() {
+		inst.applySpeed(inst.clampSpeed(ratio))
+	}
+}
+
+// cancelTempoRamp stops a running tempo ramp, if any. Every tempo command calls
+// it so the latest instruction wins.
+func (dj *DJMixer) cancelTempoRamp() {
+	dj.mu.Lock()
+	defer dj.mu.Unlock()
+	if dj.cancelRamp != nil {
+		dj.cancelRamp()
+		dj.cancelRamp = nil
+	}
+}
+
+// RampMasterBPM glides the master tempo from its current value to target over
+// duration in a background goroutine.
+func (dj *DJMixer) RampMasterBPM(target float64, duration time.Duration) error {
+	ratio := target / BaseBPM
+	if !within(ratio, MinSpeedRatio, MaxSpeedRatio) {
+		return errorf(ErrOutOfRange, "BPM alvo %.1f está fora do intervalo [%.1f, %.1f]", target, BaseBPM*MinSpeedRatio, BaseBPM*MaxSpeedRatio)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("duração da rampa deve ser positiva")
+	}
+	dj.cancelTempoRamp()
+	ctx, cancel := context.WithCancel(context.Background())
+	dj.mu.Lock()
+	dj.cancelRamp = cancel
+	dj.mu.Unlock()
+
+	from := dj.MasterBPM()
+	log.Printf("📈 Rampa de BPM mestre de %.1f para %.1f em %s.", from, target, duration)
+	go func() {
+		done := runRamp(ctx, duration, func(frac float64) {
+			dj.setMasterBPM(lerp(from, target, frac))
+		})
+		if done {
+			log.Printf("🥁 Rampa concluída: BPM mestre em %.1f.", target)
+		}
+	}()
+	return nil
+}
+
+// Swing returns the master swing in percent.
+func (dj *DJMixer) Swing() float64 {
+	return dj.clock.Swing()
+}
+
+// SetSwing delays the off-beat eighths of the master clock by percent of an
+// eighth note; 0 is straight timing and about 33 a triplet shuffle.
+func (dj *DJMixer) SetSwing(percent float64) error {
+	if !within(percent, 0, MaxSwing) {
+		return errorf(ErrOutOfRange, "swing %.0f%% está fora do intervalo [0, %.0f]", percent, MaxSwing)
+	}
+	dj.clock.SetSwing(percent)
+	if percent == 0 {
+		log.Println("🎷 Swing desligado: tempo reto.")
+	} else {
+		log.Printf("🎷 Swing definido para %.0f%%.", percent)
+	}
+	return nil
+}
+
+// SetTempoFollow arms or disarms tempo follow: the next instrument played on
+// its own sets the master BPM to its tempo, once, saving a masterbpm step.
+func (dj *DJMixer) SetTempoFollow(on bool) {
+	dj.mu.Lock()
+	dj.tempoFollow = on
+	dj.mu.Unlock()
+	if on {
+		log.Println("🥁 Tempo follow ligado: o próximo instrumento tocado define o BPM mestre.")
+	} else {
+		log.Println("🥁 Tempo follow desligado.")
+	}
+}
+
+// TempoFollow reports whether the next play will set the master BPM.
+func (dj *DJMixer) TempoFollow() bool {
+	dj.mu.RLock()
+	defer dj.mu.RUnlock()
+	return dj.tempoFollow
+}
+
+// followTempo adopts inst's tempo as the master BPM if tempo follow is armed,
+// and disarms it. Files carry no tempo of their own, so an instrument's tempo
+// is BaseBPM at its current speed, as set with bpm.
+func (dj *DJMixer) followTempo(inst *Instrument) {
+	dj.mu.Lock()
+	armed := dj.tempoFollow
+	dj.tempoFollow = false
+	dj.mu.Unlock()
+	if !armed {
+		return
+	}
+	bpm := BaseBPM * inst.SpeedRatio()
+	if bpm == dj.MasterBPM() {
+		log.Printf("🥁 Tempo follow: '%s' já está no BPM mestre (%.1f).", inst.name, bpm)
+		return
+	}
+	dj.cancelTempoRamp()
+	if err := dj.SetMasterBPM(bpm); err != nil {
+		log.Printf("⚠️  Tempo follow: %v", err)
+	}
+}
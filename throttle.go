@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// throttledCommands are the parameter changes automation tends to flood. Each
+// maps to the name its bursts are grouped under, so "vol" and "volume" share one.
+var throttledCommands = map[string]string{
+	"volume": "volume", "vol": "volume", "bpm": "bpm", "trim": "trim",
+	"flanger": "flanger", "chorus": "chorus", "drive": "drive", "width": "width",
+}
+
+// ErrHeld is returned for a command the throttle held back: it has not run
+// yet, and runs when its parameter's interval ends unless a newer change to the
+// same parameter replaces it. When it runs, its output goes to the writer the
+// command came in on, followed by an "aplicado" or "erro em" line.
+var ErrHeld = errors.New("comando agrupado: será aplicado ao fim do intervalo")
+
+// throttleKey returns the parameter a command changes, e.g. "volume drums", and
+// whether it may be coalesced. Relative volume changes are never dropped, since
+// each one counts.
+func throttleKey(parts []string) (string, bool) {
+	name, ok := throttledCommands[parts[0]]
+	if !ok || len(parts) < 3 {
+		return "", false
+	}
+	if name == "volume" && strings.HasPrefix(parts[2], "+") {
+		return "", false
+	}
+	return name + " " + parts[1], true
+}
+
+// commandThrottle coalesces rapid changes to the same parameter, so a flood of
+// commands doesn't keep the audio thread waiting on speaker.Lock. The first
+// change to a parameter applies at once; any that follow within interval are
+// held, and only the latest is applied when the interval ends.
+type commandThrottle struct {
+	interval time.Duration
+	apply    func(input string, out io.Writer)
+
+	mu      sync.Mutex
+	open    map[string]bool        // parameters inside their interval
+	pending map[string]heldCommand // latest held command per parameter
+}
+
+// heldCommand is a command waiting for its interval to end, with the writer of
+// the front-end it came from.
+type heldCommand struct {
+	input string
+	out   io.Writer
+}
+
+func newCommandThrottle(interval time.Duration, apply func(input string, out io.Writer)) *commandThrottle {
+	return &commandThrottle{
+		interval: interval,
+		apply:    apply,
+		open:     make(map[string]bool),
+		pending:  make(map[string]heldCommand),
+	}
+}
+
+// hold reports whether input was held back to be applied later. A nil throttle
+// holds nothing.
+func (t *commandThrottle) hold(input string, out io.Writer) bool {
+	if t == nil {
+		return false
+	}
+	key, ok := throttleKey(strings.Fields(strings.ToLower(input)))
+	if !ok {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.open[key] {
+		t.pending[key] = heldCommand{input, out}
+		return true
+	}
+	t.open[key] = true
+	time.AfterFunc(t.interval, func() { t.flush(key) })
+	return false
+}
+
+// flush ends key's interval, applying the held command if there is one and
+// starting a new interval after it.
+func (t *commandThrottle) flush(key string) {
+	t.mu.Lock()
+	cmd, held := t.pending[key]
+	delete(t.pending, key)
+	if !held {
+		delete(t.open, key)
+		t.mu.Unlock()
+		return
+	}
+	time.AfterFunc(t.interval, func() { t.flush(key) })
+	t.mu.Unlock()
+	t.apply(cmd.input, cmd.out)
+}
+
+// SetThrottle coalesces parameter changes arriving faster than interval; zero
+// applies every command as it comes.
+func (dj *DJMixer) SetThrottle(interval time.Duration) {
+	if interval <= 0 {
+		dj.throttle = nil
+		return
+	}
+	dj.throttle = newCommandThrottle(interval, func(input string, out io.Writer) {
+		var err error
+		defer func() {
+			if v := recover(); v != nil {
+				err = reportPanic(fmt.Sprintf("comando '%s'", input), v, debug.Stack())
+			}
+			// The caller was answered with ErrHeld; this is how it learns
+			// whether the command went through after all.
+			if err != nil {
+				fmt.Fprintf(out, "erro em '%s': %v\n", input, err)
+			} else {
+				fmt.Fprintf(out, "aplicado: '%s'\n", input)
+			}
+		}()
+		err = runCommand(dj, out, input)
+	})
+	log.Printf("🚦 Mudanças de parâmetro agrupadas a cada %s.", interval)
+}
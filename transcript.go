@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// minTranscriptWait is the shortest pause worth writing to a transcript.
+const minTranscriptWait = 10 * time.Millisecond
+
+// transcript appends every command to a file as a script that replays the
+// session: each command follows a comment with its time, and a wait line
+// reproduces the pause before it. Piping the file back into the program
+// performs the session again.
+type transcript struct {
+	mu   sync.Mutex
+	file *os.File
+	last time.Time // when the previous command finished
+}
+
+func openTranscript(path string) (*transcript, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir transcrição %s: %w", path, err)
+	}
+	fmt.Fprintf(f, "# go-dj: sessão iniciada em %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	return &transcript{file: f}, nil
+}
+
+// record writes input to the transcript and returns a function to call once
+// the command has run, so time spent inside a command, such as a wait, isn't
+// counted again as a pause. A nil transcript records nothing.
+func (t *transcript) record(input string) (done func()) {
+	if t == nil {
+		return func() {}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if !t.last.IsZero() {
+		if gap := now.Sub(t.last); gap >= minTranscriptWait {
+			fmt.Fprintf(t.file, "wait %.3f\n", gap.Seconds())
+		}
+	}
+	if _, err := fmt.Fprintf(t.file, "# %s\n%s\n", now.Format("15:04:05.000"), input); err != nil {
+		log.Printf("⚠️  Falha ao gravar a transcrição: %v", err)
+	}
+	t.last = now
+	return func() {
+		t.mu.Lock()
+		t.last = time.Now()
+		t.mu.Unlock()
+	}
+}
+
+func (t *transcript) Close() error {
+	if t == nil {
+		return nil
+	}
+	return t.file.Close()
+}
+
+// StartTranscript records every command from now on to the file at path.
+func (dj *DJMixer) StartTranscript(path string) error {
+	t, err := openTranscript(path)
+	if err != nil {
+		return err
+	}
+	dj.mu.Lock()
+	dj.transcript = t
+	dj.mu.Unlock()
+	log.Printf("📝 Comandos sendo gravados em '%s'.", path)
+	return nil
+}
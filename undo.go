@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// MaxUndoDepth bounds how many reversible actions are remembered.
+const MaxUndoDepth = 32
+
+// undoAction remembers how to revert a single parameter change.
+type undoAction struct {
+	description string
+	restore     func() error
+}
+
+// undoStack is a bounded LIFO of reversible actions, safe for concurrent use
+// by the stdin loop and TCP clients.
+type undoStack struct {
+	mu      sync.Mutex
+	actions []undoAction
+}
+
+func (s *undoStack) Push(description string, restore func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions = append(s.actions, undoAction{description: description, restore: restore})
+	if len(s.actions) > MaxUndoDepth {
+		s.actions = s.actions[len(s.actions)-MaxUndoDepth:]
+	}
+}
+
+func (s *undoStack) Pop() (undoAction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.actions) == 0 {
+		return undoAction{}, false
+	}
+	last := s.actions[len(s.actions)-1]
+	s.actions = s.actions[:len(s.actions)-1]
+	return last, true
+}
+
+// Undo reverts the most recent volume or BPM change made through a command.
+func (dj *DJMixer) Undo() error {
+	action, ok := dj.undo.Pop()
+	if !ok {
+		return fmt.Errorf("nada para desfazer")
+	}
+	if err := action.restore(); err != nil {
+		return fmt.Errorf("falha ao desfazer %s: %w", action.description, err)
+	}
+	log.Printf("↩️  Desfeito: %s.", action.description)
+	return nil
+}
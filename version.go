@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information, injected at build time with
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD)"
+var (
+	version = "dev"
+	commit  = ""
+)
+
+// buildInfo describes the running binary, for the version command and for
+// bug reports.
+type buildInfo struct {
+	Version     string
+	Commit      string
+	GoVersion   string
+	BeepVersion string
+}
+
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:     version,
+		Commit:      commit,
+		GoVersion:   runtime.Version(),
+		BeepVersion: "desconhecida",
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path == "github.com/faiface/beep" {
+			info.BeepVersion = dep.Version
+			if dep.Replace != nil {
+				info.BeepVersion += " (substituída por " + dep.Replace.Path + ")"
+			}
+		}
+	}
+	// Without ldflags, fall back to the VCS stamp go build embeds on its own.
+	if info.Commit == "" {
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" && len(s.Value) >= 7 {
+				info.Commit = s.Value[:7]
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "desconhecido"
+	}
+	return info
+}
+
+func printVersion(out io.Writer) {
+	info := readBuildInfo()
+	fmt.Fprintf(out, "Go DJ %s (commit %s)\n", info.Version, info.Commit)
+	fmt.Fprintf(out, "  Go:   %s\n", info.GoVersion)
+	fmt.Fprintf(out, "  beep: %s\n", info.BeepVersion)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/faiface/beep/wav"
+)
+
+const (
+	// waveformResolution is how many buckets the cached envelope keeps; coarser
+	// views are merged from it without decoding the file again.
+	waveformResolution = 4096
+	MaxWaveformBuckets = waveformResolution
+	waveformRows       = 9
+)
+
+// waveBucket is the sample range of one slice of the file, both channels
+// together.
+type waveBucket struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Waveform returns a min/max envelope of the whole file in the given number of
+// buckets. The file is decoded once, on a separate reader so playback is not
+// disturbed, and the envelope is cached on the instrument.
+func (i *Instrument) Waveform(buckets int) ([]waveBucket, error) {
+	if buckets < 1 || buckets > MaxWaveformBuckets {
+		return nil, errorf(ErrOutOfRange, "número de colunas %d está fora do intervalo [1, %d]", buckets, MaxWaveformBuckets)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.waveform == nil {
+		env, err := readEnvelope(i.path)
+		if err != nil {
+			return nil, err
+		}
+		i.waveform = env
+	}
+	base := i.waveform
+	if buckets > len(base) {
+		buckets = len(base)
+	}
+	out := make([]waveBucket, buckets)
+	for k := range out {
+		lo, hi := k*len(base)/buckets, (k+1)*len(base)/buckets
+		b := base[lo]
+		for _, c := range base[lo+1 : hi] {
+			b.Min = math.Min(b.Min, c.Min)
+			b.Max = math.Max(b.Max, c.Max)
+		}
+		out[k] = b
+	}
+	return out, nil
+}
+
+// readEnvelope decodes path and reduces it to waveformResolution buckets (or
+// one per sample for very short files).
+func readEnvelope(path string) ([]waveBucket, error) {
+	if path == "" {
+		return nil, fmt.Errorf("instrumento não vem de um arquivo")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir arquivo %s: %w", path, err)
+	}
+	defer f.Close()
+	streamer, _, err := wav.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao decodificar arquivo WAV %s: %w", path, err)
+	}
+	length := streamer.Len()
+	if length == 0 {
+		return nil, fmt.Errorf("arquivo %s está vazio", path)
+	}
+	n := waveformResolution
+	if length < n {
+		n = length
+	}
+	env := make([]waveBucket, n)
+	for k := range env {
+		env[k] = waveBucket{Min: math.Inf(1), Max: math.Inf(-1)}
+	}
+	buf := make([][2]float64, 4096)
+	pos := 0
+	for {
+		sn, ok := streamer.Stream(buf)
+		for _, s := range buf[:sn] {
+			b := &env[pos*n/length]
+			b.Min = math.Min(b.Min, math.Min(s[0], s[1]))
+			b.Max = math.Max(b.Max, math.Max(s[0], s[1]))
+			pos++
+		}
+		if !ok || pos >= length {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao ler %s: %w", path, err)
+	}
+	for k := range env {
+		if math.IsInf(env[k].Min, 1) { // a bucket the decoder never reached
+			env[k] = waveBucket{}
+		}
+	}
+	return env, nil
+}
+
+// printWaveform draws the envelope as rows of characters, top to bottom from
+// full scale positive to full scale negative.
+func printWaveform(out io.Writer, name string, buckets []waveBucket) {
+	fmt.Fprintf(out, "--- %s ---\n", name)
+	for r := 0; r < waveformRows; r++ {
+		// Each row covers a band of amplitudes centred on level.
+		level := 1 - 2*float64(r)/float64(waveformRows-1)
+		half := 1 / float64(waveformRows-1)
+		var b strings.Builder
+		for _, bucket := range buckets {
+			if bucket.Max >= level-half && bucket.Min <= level+half {
+				b.WriteRune('█')
+			} else if r == waveformRows/2 {
+				b.WriteRune('─')
+			} else {
+				b.WriteRune(' ')
+			}
+		}
+		fmt.Fprintln(out, b.String())
+	}
+}